@@ -9,10 +9,12 @@ import (
 	"strings"
 	"time"
 	
+	"hipaa-app/internal/content"
 	"hipaa-app/internal/pdf"
 	"hipaa-app/internal/risk"
 	"hipaa-app/internal/scheduler"
 	"hipaa-app/internal/storage"
+	"hipaa-app/internal/watcher"
 	
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -25,6 +27,7 @@ type App struct {
 	pdfService *pdf.PDFService
 	store      *storage.Store
 	scheduler  *scheduler.Scheduler
+	sentinel   *watcher.Watcher
 }
 
 // NewApp creates a new App application struct
@@ -37,6 +40,7 @@ func NewApp() *App {
 		pdfService: pdf.NewPDFService(),
 		store:      store,
 	}
+	app.loadLiabilityModel()
 	
 	return app
 }
@@ -46,28 +50,14 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	
-	// Initialize scheduler with event handler
-	eventHandler := func(event string, data interface{}) {
-		runtime.EventsEmit(ctx, event, data)
-		
-		// System notifications
-		if event == "scan:scheduled:complete" {
-			if dataMap, ok := data.(map[string]interface{}); ok {
-				if status, ok := dataMap["status"].(string); ok {
-					if status == "FAILED" {
-						runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
-							Type:    runtime.WarningDialog,
-							Title:   "Scheduled Scan Alert",
-							Message: "Automated scan detected HIPAA risks. Review required.",
-						})
-					}
-				}
-			}
-		}
-	}
-	
-	a.scheduler = scheduler.NewScheduler(a.riskEngine, a.pdfService, a.store, eventHandler)
+	// Initialize scheduler; system notifications follow each job's policy
+	a.scheduler = scheduler.NewScheduler(a.riskEngine, a.pdfService, a.store, a.schedulerEmitter())
 	a.scheduler.Start(ctx)
+	
+	// Real-time watcher on the scan paths (Active Sentinel)
+	a.sentinel = watcher.NewWatcher(a.riskEngine, a.sentinelEmitter())
+	a.sentinel.SetRules(a.scanRulesFor)
+	a.startSentinelIfEnabled()
 }
 
 // shutdown is called when the app is closing
@@ -75,6 +65,9 @@ func (a *App) shutdown(ctx context.Context) {
 	if a.scheduler != nil {
 		a.scheduler.Stop()
 	}
+	if a.sentinel != nil {
+		a.sentinel.Stop()
+	}
 }
 
 type ScanResult struct {
@@ -117,6 +110,11 @@ func (a *App) AnalyzeFile(path string) (ScanResult, error) {
 	return result, nil
 }
 
+// supportedFilesPattern lists the usual names of formats content.ExtractText
+// reads. Files are identified by their contents, so one picked through
+// "All Files" is analyzed the same way.
+const supportedFilesPattern = "*.txt;*.log;*.md;*.csv;*.tsv;*.json;*.xml;*.html;*.pdf;*.docx;*.xlsx;*.pptx;*.odt;*.ods;*.odp;*.doc;*.xls;*.ppt;*.rtf;*.eml;*.mbox;*.msg;*.jpg;*.jpeg;*.png;*.gif;*.bmp;*.tif;*.tiff"
+
 // SelectFile opens a native file dialog and returns the selected path
 func (a *App) SelectFile() (string, error) {
 	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
@@ -124,7 +122,7 @@ func (a *App) SelectFile() (string, error) {
 		Filters: []runtime.FileFilter{
 			{
 				DisplayName: "Supported Files",
-				Pattern:     "*.txt;*.log;*.md;*.csv;*.json;*.pdf;*.docx;*.xlsx;*.jpg;*.jpeg;*.png",
+				Pattern:     supportedFilesPattern,
 			},
 			{
 				DisplayName: "All Files",
@@ -146,7 +144,7 @@ func (a *App) SelectFiles() ([]string, error) {
 		Filters: []runtime.FileFilter{
 			{
 				DisplayName: "Supported Files",
-				Pattern:     "*.txt;*.log;*.md;*.csv;*.json;*.pdf;*.docx;*.xlsx;*.jpg;*.jpeg;*.png",
+				Pattern:     supportedFilesPattern,
 			},
 			{
 				DisplayName: "All Files",
@@ -186,7 +184,12 @@ func (a *App) ScanDirectory(path string) (risk.AuditReport, error) {
 		runtime.EventsEmit(a.ctx, "scan:progress", currentPath)
 	}
 
-	report, err := a.riskEngine.AnalyzeDirectory(scanCtx, path, progress)
+	// Apply the configured include/exclude rules for this folder
+	opts := a.riskEngine.ScanOptions()
+	if config, err := a.store.Load(); err == nil {
+		opts.Rules = config.RulesFor(path)
+	}
+	report, err := a.riskEngine.AnalyzeDirectoryWithOptions(scanCtx, path, opts, progress)
 	if err != nil {
 		if err == context.Canceled {
 			runtime.EventsEmit(a.ctx, "scan:cancelled", "Scan stopped by user")
@@ -200,6 +203,24 @@ func (a *App) ScanDirectory(path string) (risk.AuditReport, error) {
 	return report, nil
 }
 
+// SetScanOptions configures parallel scanning: the number of files analyzed
+// at once (0 = one per CPU) and the per-file timeout in seconds (0 = none)
+func (a *App) SetScanOptions(workers int, fileTimeoutSeconds int) {
+	a.riskEngine.SetScanOptions(risk.ScanOptions{
+		Workers:     workers,
+		FileTimeout: time.Duration(fileTimeoutSeconds) * time.Second,
+	})
+}
+
+// ClearScanCache forgets cached file results so the next scheduled scan
+// re-analyzes every file
+func (a *App) ClearScanCache() error {
+	if a.store == nil {
+		return fmt.Errorf("storage unavailable")
+	}
+	return a.store.ClearFingerprints()
+}
+
 // CancelScan aborts the running directory scan
 func (a *App) CancelScan() {
 	if a.cancelScan != nil {
@@ -217,6 +238,7 @@ func (a *App) CancelScheduledScan() {
 
 // OpenPath opens a file or directory using the system default application
 func (a *App) OpenPath(path string) error {
+	path = content.ContainerPath(path) // A file inside an archive opens the archive
 	runtime.BrowserOpenURL(a.ctx, "file://"+path) // Try Wails native first
 	// If Wails blocks file://, we might need exec.Command "open" on mac
 	// But let's try to just return the path to frontend ?? 
@@ -227,6 +249,9 @@ func (a *App) OpenPath(path string) error {
 	return cmd.Start()
 }
 
+// maxReportFindings caps the per-finding table in the audit PDF
+const maxReportFindings = 500
+
 // GenerateReport creates a PDF from the audit results
 func (a *App) GenerateReport(report risk.AuditReport) (string, error) {
 	// Prompt user for save location
@@ -266,22 +291,78 @@ func (a *App) GenerateReport(report risk.AuditReport) (string, error) {
 
 	// If Risks: Generate Audit Report
 	var offenders [][]string
+	var findings [][]string
 	for _, off := range report.TopOffenders {
 		offenders = append(offenders, []string{
 			off.RiskLabel,
 			off.FilePath,
 			fmt.Sprintf("$%d", off.EstimatedFine),
 		})
+		for _, f := range off.Details {
+			if len(findings) >= maxReportFindings {
+				break
+			}
+			findings = append(findings, []string{
+				filepath.Base(off.FilePath),
+				f.Location(),
+				f.Detector,
+				f.Category,
+				fmt.Sprintf("%d%%", int(f.Confidence*100)),
+			})
+		}
 	}
 	
-	return a.pdfService.GenerateAuditReport(report.TotalFiles, report.CriticalCount, report.PotentialLiability, offenders, savePath)
+	summary := pdf.AuditSummary{
+		TotalFiles:      report.TotalFiles,
+		CriticalCount:   report.CriticalCount,
+		Liability:       report.PotentialLiability,
+		Individuals:     report.EstimatedIndividuals,
+		LiabilityModel:  fmt.Sprintf("%s v%s", report.Liability.Model, report.Liability.Version),
+		Assumptions:     report.Liability.Assumptions,
+		SkippedBinaries: report.SkippedBinaries,
+	}
+	for _, u := range report.Unscanned {
+		summary.Unscanned = append(summary.Unscanned, []string{filepath.Base(u.Path), u.Format, u.Reason})
+	}
+	return a.pdfService.GenerateAuditReport(summary, offenders, findings, savePath)
 }
 // RedactFile creates a sanitized copy of the file
 func (a *App) RedactFile(path string) (string, error) {
+	return a.RedactFileWithMode(path, string(risk.RedactFull))
+}
+
+// RedactFileWithMode creates a sanitized copy using the given redaction mode:
+// "full" removes every identifier, "safe_harbor" keeps years and reports ages over 89 as "90+"
+func (a *App) RedactFileWithMode(path string, mode string) (string, error) {
+	redactionMode := risk.RedactionMode(mode)
+	if redactionMode != risk.RedactFull && redactionMode != risk.RedactSafeHarbor {
+		return "", fmt.Errorf("unknown redaction mode: %s", mode)
+	}
+	if content.ContainerPath(path) != path {
+		return "", fmt.Errorf("cannot redact a file inside an archive; extract %s first", filepath.Base(path))
+	}
+	
 	ext := strings.ToLower(filepath.Ext(path))
 	
+	// Handle Tabular Formats: blank PHI columns, redact the rest, keep CSV/XLSX valid
+	if content.IsTabular(path) {
+		newPath := fmt.Sprintf("%s_CLEANED%s", strings.TrimSuffix(path, ext), ext)
+		if err := a.riskEngine.RedactTable(path, newPath, redactionMode); err != nil {
+			return "", fmt.Errorf("table redaction failed: %w", err)
+		}
+		return newPath, nil
+	}
+	
+	format, err := content.DetectFormat(path)
+	if err != nil {
+		return "", err
+	}
+	if !format.Supported() {
+		return "", fmt.Errorf("cannot redact %s: %s files are not supported", filepath.Base(path), format)
+	}
+	
 	// Handle Binary Formats: Extract Text -> Redact -> Save as .txt
-	if ext == ".pdf" || ext == ".docx" || ext == ".xlsx" {
+	if format != content.FormatText && format != content.FormatCSV && format != content.FormatTSV {
 		// 1. Extract Text
 		rawText, err := a.riskEngine.ExtractText(path) // Helper we need to expose or use content package directly
 		if err != nil {
@@ -289,7 +370,7 @@ func (a *App) RedactFile(path string) (string, error) {
 		}
 		
 		// 2. Redact
-		redactedContent := a.riskEngine.RedactContent([]byte(rawText))
+		redactedContent := a.riskEngine.RedactContentMode([]byte(rawText), redactionMode)
 		
 		// 3. Save as .txt (Safe for LLM)
 		barePath := strings.TrimSuffix(path, ext)
@@ -311,7 +392,7 @@ func (a *App) RedactFile(path string) (string, error) {
 		return "", err
 	}
 	
-	redactedContent := a.riskEngine.RedactContent(content)
+	redactedContent := a.riskEngine.RedactContentMode(content, redactionMode)
 	
 	barePath := strings.TrimSuffix(path, ext)
 	newPath := fmt.Sprintf("%s_CLEANED%s", barePath, ext)
@@ -323,3 +404,18 @@ func (a *App) RedactFile(path string) (string, error) {
 	
 	return newPath, nil
 }
+
+// RedactColumns creates a copy of a CSV/XLSX file with the named columns
+// blanked, leaving every other cell untouched
+func (a *App) RedactColumns(path string, columns []string) (string, error) {
+	if !content.IsTabular(path) {
+		return "", fmt.Errorf("column redaction needs a CSV or XLSX file: %s", filepath.Base(path))
+	}
+	
+	ext := filepath.Ext(path)
+	newPath := fmt.Sprintf("%s_CLEANED%s", strings.TrimSuffix(path, ext), ext)
+	if err := a.riskEngine.BlankColumns(path, newPath, columns); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
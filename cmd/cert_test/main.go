@@ -33,7 +33,7 @@ func main() {
 		},
 	}
 	
-	path, err := pdfService.GenerateComplianceCertificate(100, hostname, auditHistory)
+	path, err := pdfService.GenerateComplianceCertificate(100, hostname, auditHistory, "")
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
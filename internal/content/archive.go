@@ -0,0 +1,374 @@
+package content
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveSeparator joins a container's path to the path of a file inside it,
+// e.g. "export.zip!/2024/patients.csv"
+const ArchiveSeparator = "!/"
+
+var (
+	// ErrEncrypted is reported for password-protected archives and Office
+	// documents, whose contents cannot be read and so cannot be called clean
+	ErrEncrypted = errors.New("encrypted container")
+	// ErrArchiveLimit is reported for archive contents skipped because they
+	// exceed an ArchiveLimits bound
+	ErrArchiveLimit = errors.New("archive limit exceeded")
+)
+
+// ArchiveLimits bound the work done expanding one archive, so a zip bomb
+// cannot exhaust memory. Zero fields use the DefaultArchiveLimits value.
+type ArchiveLimits struct {
+	MaxDepth       int   `json:"max_depth"`        // Archives nested inside the top-level one
+	MaxMemberBytes int64 `json:"max_member_bytes"` // Uncompressed size of one file
+	MaxTotalBytes  int64 `json:"max_total_bytes"`  // Uncompressed bytes read from the whole archive
+	MaxRatio       int64 `json:"max_ratio"`        // Uncompressed to compressed size of one file
+	MaxMembers     int   `json:"max_members"`      // Files read from the whole archive
+}
+
+// DefaultArchiveLimits allows three levels of nesting, 64 MiB files, 512 MiB
+// in total and 100:1 compression
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxDepth:       3,
+		MaxMemberBytes: 64 << 20,
+		MaxTotalBytes:  512 << 20,
+		MaxRatio:       100,
+		MaxMembers:     10000,
+	}
+}
+
+func (l ArchiveLimits) withDefaults() ArchiveLimits {
+	d := DefaultArchiveLimits()
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = d.MaxDepth
+	}
+	if l.MaxMemberBytes <= 0 {
+		l.MaxMemberBytes = d.MaxMemberBytes
+	}
+	if l.MaxTotalBytes <= 0 {
+		l.MaxTotalBytes = d.MaxTotalBytes
+	}
+	if l.MaxRatio <= 0 {
+		l.MaxRatio = d.MaxRatio
+	}
+	if l.MaxMembers <= 0 {
+		l.MaxMembers = d.MaxMembers
+	}
+	return l
+}
+
+// ratioFloor exempts small files from MaxRatio: a few KiB of repeated text
+// legitimately compresses far better than 100:1
+const ratioFloor = 1 << 20
+
+// IsArchive reports whether WalkArchive expands files of this format.
+// Mail files count: a message is walked as its text plus its attachments,
+// and a mailbox as a folder per message. OOXML and OpenDocument files are
+// ZIPs too, but are extracted as documents.
+func (f Format) IsArchive() bool {
+	switch f {
+	case FormatZIP, FormatGZIP, FormatTAR, FormatEML, FormatMBOX, FormatMSG:
+		return true
+	}
+	return false
+}
+
+// Member is one file found inside an archive. Either Data holds its
+// uncompressed contents or Err says why it could not be read (ErrEncrypted,
+// ErrArchiveLimit or a corrupt entry).
+type Member struct {
+	Path   string // Virtual path: the container path, "!/", then the path inside
+	Format Format // Detected from Data; FormatUnknown if it could not be read
+	Data   []byte
+	Err    error
+}
+
+// ContainerPath returns the file on disk a virtual archive path points into
+func ContainerPath(virtual string) string {
+	if i := strings.Index(virtual, ArchiveSeparator); i >= 0 {
+		return virtual[:i]
+	}
+	return virtual
+}
+
+// WalkArchive calls fn for every file inside the ZIP, TAR, GZIP or mail file
+// at path, in archive order, expanding nested archives up to limits.MaxDepth.
+// Contents are decompressed in memory; nothing is written to disk. A member
+// that cannot be read is passed to fn with Err set. The returned error is
+// non-nil if the archive cannot be opened, if the walk stopped early on a
+// limit, or if fn returned an error; members already passed to fn stand.
+func WalkArchive(path string, limits ArchiveLimits, fn func(Member) error) error {
+	src, err := openSource(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return walkSource(src, detect(src), limits, fn)
+}
+
+func walkSource(src source, format Format, limits ArchiveLimits, fn func(Member) error) error {
+	w := &archiveWalker{limits: limits.withDefaults(), fn: fn}
+	path := src.name
+	var err error
+	switch format {
+	case FormatZIP:
+		err = w.walkZip(path, src.r, src.size, 0)
+	case FormatGZIP:
+		err = w.walkGzip(path, src.reader(), src.size, 0)
+	case FormatTAR:
+		err = w.walkTar(path, src.reader(), 0)
+	case FormatEML:
+		err = w.walkEML(path, src.reader(), 0)
+	case FormatMBOX:
+		err = w.walkMBOX(path, src.reader(), 0)
+	case FormatMSG:
+		err = w.walkMSG(path, src.r, 0)
+	default:
+		err = fmt.Errorf("not an archive: %s", format)
+	}
+	var stop errStop
+	if errors.As(err, &stop) {
+		return stop.err
+	}
+	return err
+}
+
+// archiveWalker carries the limits and running totals of one WalkArchive
+type archiveWalker struct {
+	limits  ArchiveLimits
+	total   int64 // uncompressed bytes read so far
+	members int
+	fn      func(Member) error
+}
+
+// errStop ends the whole walk, not just the archive being read: the total
+// budget is spent or fn failed
+type errStop struct{ err error }
+
+func (e errStop) Error() string { return e.err.Error() }
+func (e errStop) Unwrap() error { return e.err }
+
+func (w *archiveWalker) emit(m Member) error {
+	if err := w.fn(m); err != nil {
+		return errStop{err}
+	}
+	return nil
+}
+
+func (w *archiveWalker) walkZip(container string, r io.ReaderAt, size int64, depth int) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	encrypted, files := 0, 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		files++
+		if f.Flags&0x1 != 0 { // Traditional PKWARE or AES encryption
+			encrypted++
+			continue
+		}
+		name := join(container, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			if err := w.emit(Member{Path: name, Format: FormatUnknown, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := w.read(rc, int64(f.CompressedSize64))
+		rc.Close()
+		if err := w.member(name, data, err, depth); err != nil {
+			return err
+		}
+	}
+	if encrypted > 0 {
+		return w.emit(Member{Path: container, Format: FormatZIP, Err: fmt.Errorf("%w: %d of %d files are password-protected", ErrEncrypted, encrypted, files)})
+	}
+	return nil
+}
+
+func (w *archiveWalker) walkGzip(container string, r io.Reader, compressed int64, depth int) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	// A .tar.gz is one archive: its files appear directly under the container
+	br := bufio.NewReaderSize(&ratioReader{r: zr, limit: w.ratioLimit(compressed), ratio: w.limits.MaxRatio}, 512)
+	if head, _ := br.Peek(262); sniff(head, "") == FormatTAR {
+		return w.walkTar(container, br, depth)
+	}
+
+	name := zr.Name // Original file name, if the compressor kept it
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(filepath.ToSlash(container)), ".gz")
+	}
+	data, err := w.read(br, compressed)
+	return w.member(join(container, name), data, err, depth)
+}
+
+func (w *archiveWalker) walkTar(container string, r io.Reader, depth int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := w.read(tr, -1) // Stored, not compressed
+		if err := w.member(join(container, hdr.Name), data, err, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// member hands one file to fn, expanding it first if it is itself an archive
+func (w *archiveWalker) member(name string, data []byte, readErr error, depth int) error {
+	var stop errStop
+	if errors.As(readErr, &stop) {
+		return stop
+	}
+	if readErr != nil {
+		return w.emit(Member{Path: name, Format: FormatUnknown, Err: readErr})
+	}
+
+	format := DetectFormatBytes(data, name)
+	if !format.IsArchive() {
+		return w.emit(Member{Path: name, Format: format, Data: data})
+	}
+	if depth+1 > w.limits.MaxDepth {
+		return w.emit(Member{Path: name, Format: format, Err: fmt.Errorf("%w: nested deeper than %d archives", ErrArchiveLimit, w.limits.MaxDepth)})
+	}
+	var err error
+	switch format {
+	case FormatZIP:
+		err = w.walkZip(name, bytes.NewReader(data), int64(len(data)), depth+1)
+	case FormatGZIP:
+		err = w.walkGzip(name, bytes.NewReader(data), int64(len(data)), depth+1)
+	case FormatTAR:
+		err = w.walkTar(name, bytes.NewReader(data), depth+1)
+	case FormatEML:
+		err = w.walkEML(name, bytes.NewReader(data), depth+1)
+	case FormatMBOX:
+		err = w.walkMBOX(name, bytes.NewReader(data), depth+1)
+	case FormatMSG:
+		err = w.walkMSG(name, bytes.NewReader(data), depth+1)
+	}
+	if err != nil && !errors.As(err, &stop) {
+		// A corrupt nested archive does not end the walk of its parent
+		return w.emit(Member{Path: name, Format: format, Err: err})
+	}
+	return err
+}
+
+// read loads one member, enforcing the per-file, total, ratio and count
+// limits. compressed is the member's compressed size, or -1 if stored.
+func (w *archiveWalker) read(r io.Reader, compressed int64) ([]byte, error) {
+	if err := w.count(); err != nil {
+		return nil, err
+	}
+	remaining := w.limits.MaxTotalBytes - w.total
+	limit := w.limits.MaxMemberBytes
+	if remaining < limit {
+		limit = remaining
+	}
+	ratio := w.ratioLimit(compressed)
+	if ratio >= 0 && ratio < limit {
+		limit = ratio
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	w.total += int64(len(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) <= limit {
+		return data, nil
+	}
+
+	switch limit {
+	case remaining:
+		return nil, errStop{fmt.Errorf("%w: more than %d bytes in total", ErrArchiveLimit, w.limits.MaxTotalBytes)}
+	case ratio:
+		return nil, fmt.Errorf("%w: compressed more than %d:1", ErrArchiveLimit, w.limits.MaxRatio)
+	default:
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrArchiveLimit, w.limits.MaxMemberBytes)
+	}
+}
+
+// count enforces MaxMembers for a member about to be read
+func (w *archiveWalker) count() error {
+	w.members++
+	if w.members > w.limits.MaxMembers {
+		return errStop{fmt.Errorf("%w: more than %d files", ErrArchiveLimit, w.limits.MaxMembers)}
+	}
+	return nil
+}
+
+// account charges n bytes read outside read, such as the streams of an
+// Outlook message, to MaxTotalBytes
+func (w *archiveWalker) account(n int64) error {
+	w.total += n
+	if w.total > w.limits.MaxTotalBytes {
+		return errStop{fmt.Errorf("%w: more than %d bytes in total", ErrArchiveLimit, w.limits.MaxTotalBytes)}
+	}
+	return nil
+}
+
+// ratioLimit is the most a member of the given compressed size may expand
+// to, or -1 for no limit
+func (w *archiveWalker) ratioLimit(compressed int64) int64 {
+	if compressed < 0 {
+		return -1
+	}
+	limit := compressed * w.limits.MaxRatio
+	if limit < ratioFloor {
+		limit = ratioFloor
+	}
+	return limit
+}
+
+// ratioReader fails once more than limit bytes have been decompressed, for
+// streams (a .tar.gz) whose members are not compressed individually. Only
+// that archive is abandoned; an outer one carries on.
+type ratioReader struct {
+	r     io.Reader
+	limit int64 // -1 for no limit
+	ratio int64 // for the error message
+	n     int64
+}
+
+func (r *ratioReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.limit >= 0 && r.n > r.limit {
+		return n, fmt.Errorf("%w: compressed more than %d:1", ErrArchiveLimit, r.ratio)
+	}
+	return n, err
+}
+
+// join builds the virtual path of a file inside container
+func join(container, name string) string {
+	return container + ArchiveSeparator + strings.TrimPrefix(path.Clean("/"+name), "/")
+}
@@ -2,56 +2,171 @@ package content
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"image"
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"os"
+	"io"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode/utf16"
 
 	"github.com/ledongthuc/pdf"
-	"github.com/nguyenthenguyen/docx"
 	"github.com/xuri/excelize/v2"
 )
 
-// ExtractText attempts to pull raw text from supported file formats.
-// Returns an error if the format is unsupported or parsing fails.
+// Version changes whenever an extractor, format detection or an archive
+// limit changes what text is produced for some file. Scan caches include it
+// in their ruleset, so bump it with every such change.
+const Version = 1
+
+// ExtractText attempts to pull raw text from supported file formats, chosen
+// by DetectFormat rather than the file name.
+// Returns an error wrapping ErrUnsupportedFormat if there is no extractor for
+// the format, or the parser's error if parsing fails.
 func ExtractText(path string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(path))
+	src, err := openSource(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	return extractText(src, detect(src))
+}
+
+// Document is extracted text along with the parts of the file each stretch
+// of it came from, for formats assembled from several parts (DOCX body,
+// headers, comments, properties)
+type Document struct {
+	Text  string
+	Parts []Part // In text order; empty when the text is one piece
+}
+
+// Part is the stretch Text[Start:End] of a Document, read from the named
+// part of the file (e.g. "word/comments.xml"). Each part starts on a new line.
+type Part struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// PartAt returns the part containing a byte offset of the text
+func (d Document) PartAt(offset int) (Part, bool) {
+	i := sort.Search(len(d.Parts), func(i int) bool { return d.Parts[i].End > offset })
+	if i < len(d.Parts) && d.Parts[i].Start <= offset {
+		return d.Parts[i], true
+	}
+	return Part{}, false
+}
+
+// ExtractDocument is ExtractText keeping track of which part of the file
+// the text came from
+func ExtractDocument(path string) (Document, error) {
+	src, err := openSource(path)
+	if err != nil {
+		return Document{}, err
+	}
+	defer src.Close()
+	return extractDocument(src, detect(src))
+}
+
+// ExtractMember extracts a file found inside an archive, using the format
+// the walk detected. Its virtual path stands in for a file path in image
+// metadata.
+func ExtractMember(m Member) (Document, error) {
+	if m.Err != nil {
+		return Document{}, m.Err
+	}
+	return extractDocument(memorySource(m.Path, m.Data), m.Format)
+}
+
+func extractDocument(src source, format Format) (Document, error) {
+	if format == FormatDOCX {
+		return extractDOCX(src)
+	}
+	text, err := extractText(src, format)
+	return Document{Text: text}, err
+}
 
-	switch ext {
-	case ".txt", ".csv", ".log", ".md", ".json", ".xml", ".html", ".js", ".ts", ".go":
+func extractText(src source, format Format) (string, error) {
+	switch format {
+	case FormatText, FormatCSV, FormatTSV:
 		// Plain text formats
-		content, err := os.ReadFile(path)
+		content, err := io.ReadAll(src.reader())
 		if err != nil {
 			return "", err
 		}
-		return string(content), nil
+		return decodeText(content), nil
+
+	case FormatPDF:
+		return extractPDF(src)
+
+	case FormatDOCX:
+		doc, err := extractDOCX(src)
+		return doc.Text, err
+
+	case FormatXLSX:
+		return extractXLSX(src)
+
+	case FormatPPTX:
+		return extractPPTX(src)
+
+	case FormatODF:
+		return extractODF(src)
+
+	case FormatDOC:
+		return extractDOC(src)
 
-	case ".pdf":
-		return extractPDF(path)
+	case FormatXLS:
+		return extractXLS(src)
 
-	case ".docx":
-		return extractDOCX(path)
+	case FormatPPT:
+		return extractPPT(src)
 
-	case ".xlsx":
-		return extractXLSX(path)
+	case FormatOLE:
+		return extractOLE(src)
+
+	case FormatRTF:
+		return extractRTF(src)
 	
-	case ".jpg", ".jpeg", ".png":
-		return extractImageMetadata(path)
+	case FormatJPEG, FormatPNG, FormatGIF, FormatBMP, FormatTIFF:
+		return extractImageMetadata(src, format)
+
+	case FormatEML, FormatMBOX, FormatMSG:
+		return extractMail(src, format)
 
 	default:
-		return "", fmt.Errorf("unsupported format: %s", ext)
+		return "", unsupported(format)
+	}
+}
+
+// decodeText converts UTF-16 text (marked by a byte order mark) to UTF-8 and
+// drops a UTF-8 byte order mark
+func decodeText(data []byte) string {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte("\xFF\xFE")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte("\xFE\xFF")):
+		order = binary.BigEndian
+	default:
+		return string(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")))
+	}
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
 	}
+	return string(utf16.Decode(units))
 }
 
-func extractPDF(path string) (string, error) {
-	f, r, err := pdf.Open(path)
+func extractPDF(src source) (string, error) {
+	r, err := pdf.NewReader(src.r, src.size)
 	if err != nil {
 		return "", err
 	}
-	defer f.Close()
 
 	var buf bytes.Buffer
 	b, err := r.GetPlainText()
@@ -62,19 +177,8 @@ func extractPDF(path string) (string, error) {
 	return buf.String(), nil
 }
 
-func extractDOCX(path string) (string, error) {
-	r, err := docx.ReadDocxFile(path)
-	if err != nil {
-		return "", err
-	}
-	defer r.Close()
-
-	content := r.Editable()
-	return content.GetContent(), nil
-}
-
-func extractXLSX(path string) (string, error) {
-	f, err := excelize.OpenFile(path)
+func extractXLSX(src source) (string, error) {
+	f, err := excelize.OpenReader(src.reader())
 	if err != nil {
 		return "", err
 	}
@@ -86,37 +190,34 @@ func extractXLSX(path string) (string, error) {
 		if err != nil {
 			continue
 		}
+		// Tab-separated so cell boundaries (and header columns) survive
 		for _, row := range rows {
-			for _, colCell := range row {
-				sb.WriteString(colCell)
-				sb.WriteString(" ")
-			}
+			sb.WriteString(strings.Join(row, "\t"))
 			sb.WriteString("\n")
 		}
 	}
 	return sb.String(), nil
 }
 
-func extractImageMetadata(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	// Decode image config to verify it's valid
-	cfg, format, err := image.DecodeConfig(file)
-	if err != nil {
-		return "", err
-	}
-
+func extractImageMetadata(src source, format Format) (string, error) {
 	// Build metadata string
-	filename := filepath.Base(path)
+	filename := filepath.Base(src.name)
 	var metadata strings.Builder
 	
 	metadata.WriteString(fmt.Sprintf("Image Analysis: %s\n", filename))
-	metadata.WriteString(fmt.Sprintf("Format: %s (%dx%d pixels)\n", format, cfg.Width, cfg.Height))
-	metadata.WriteString(fmt.Sprintf("File Path: %s\n", path))
+	
+	// Decode image config to verify it's valid; BMP and TIFF have no
+	// standard library decoder, so only their format is reported
+	if format == FormatBMP || format == FormatTIFF {
+		metadata.WriteString(fmt.Sprintf("Format: %s\n", format))
+	} else {
+		cfg, _, err := image.DecodeConfig(src.reader())
+		if err != nil {
+			return "", err
+		}
+		metadata.WriteString(fmt.Sprintf("Format: %s (%dx%d pixels)\n", format, cfg.Width, cfg.Height))
+	}
+	metadata.WriteString(fmt.Sprintf("File Path: %s\n", src.name))
 	
 	// Analyze filename for PHI indicators
 	lowerName := strings.ToLower(filename)
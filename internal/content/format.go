@@ -0,0 +1,328 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Format is a file type identified from the file's contents. The extension
+// is only a tie-breaker for formats whose bytes look alike (CSV vs. plain
+// text, the members of the OLE2 family).
+type Format string
+
+const (
+	FormatUnknown Format = "unknown"
+	FormatText    Format = "text"
+	FormatCSV     Format = "csv"
+	FormatTSV     Format = "tsv"
+	FormatPDF     Format = "pdf"
+	FormatDOCX    Format = "docx"
+	FormatXLSX    Format = "xlsx"
+	FormatPPTX    Format = "pptx"
+	FormatODF     Format = "odf"  // OpenDocument text, spreadsheet or presentation
+	FormatDOC     Format = "doc"  // Word 97-2003
+	FormatXLS     Format = "xls"  // Excel 97-2003
+	FormatPPT     Format = "ppt"  // PowerPoint 97-2003
+	FormatOLE     Format = "ole2" // Other OLE2 compound file, e.g. a password-protected OOXML file
+	FormatMSG     Format = "msg"  // Outlook message, an OLE2 container of MAPI properties
+	FormatEML     Format = "eml"  // RFC 822 / MIME message
+	FormatMBOX    Format = "mbox" // Unix mailbox of RFC 822 messages
+	FormatRTF     Format = "rtf"
+	FormatJPEG    Format = "jpeg"
+	FormatPNG     Format = "png"
+	FormatGIF     Format = "gif"
+	FormatBMP     Format = "bmp"
+	FormatTIFF    Format = "tiff"
+	FormatZIP     Format = "zip"
+	FormatGZIP    Format = "gzip"
+	FormatTAR     Format = "tar"
+)
+
+// ErrUnsupportedFormat is wrapped by extraction errors for files whose
+// format is known but has no extractor, or could not be identified
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// extractable lists the formats ExtractText can read
+var extractable = map[Format]bool{
+	FormatText: true, FormatCSV: true, FormatTSV: true,
+	FormatPDF: true, FormatDOCX: true, FormatXLSX: true, FormatPPTX: true, FormatODF: true,
+	FormatDOC: true, FormatXLS: true, FormatPPT: true, FormatRTF: true,
+	FormatJPEG: true, FormatPNG: true, FormatGIF: true, FormatBMP: true, FormatTIFF: true,
+	FormatEML: true, FormatMBOX: true, FormatMSG: true,
+}
+
+// Supported reports whether ExtractText can read files of this format
+func (f Format) Supported() bool {
+	return extractable[f]
+}
+
+// Tabular reports whether ExtractTables can read files of this format
+func (f Format) Tabular() bool {
+	return f == FormatCSV || f == FormatTSV || f == FormatXLSX
+}
+
+// sniffLen is how much of a file DetectFormat reads
+const sniffLen = 8192
+
+// source is content to extract from: a file on disk or bytes held in memory
+// (an archive member)
+type source struct {
+	name string // file path or virtual archive path; its extension breaks ties
+	r    io.ReaderAt
+	size int64
+	file *os.File // nil for memory sources
+}
+
+func openSource(path string) (source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return source{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return source{}, err
+	}
+	return source{name: path, r: f, size: info.Size(), file: f}, nil
+}
+
+func memorySource(name string, data []byte) source {
+	return source{name: name, r: bytes.NewReader(data), size: int64(len(data))}
+}
+
+// reader returns a fresh reader over the whole content
+func (s source) reader() io.Reader {
+	return io.NewSectionReader(s.r, 0, s.size)
+}
+
+func (s source) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// DetectFormat identifies a file from its leading bytes, and for ZIP-based
+// formats from the names of the parts inside
+func DetectFormat(path string) (Format, error) {
+	src, err := openSource(path)
+	if err != nil {
+		return FormatUnknown, err
+	}
+	defer src.Close()
+	return detect(src), nil
+}
+
+// DetectFormatBytes identifies content held in memory, such as a file inside
+// an archive. name supplies the extension used to break ties.
+func DetectFormatBytes(data []byte, name string) Format {
+	return detect(memorySource(name, data))
+}
+
+func detect(src source) Format {
+	head := make([]byte, sniffLen)
+	n, _ := src.r.ReadAt(head, 0)
+	format := sniff(head[:n], src.name)
+	switch format {
+	case FormatZIP:
+		format = zipFormat(src.r, src.size)
+	case FormatOLE:
+		format = oleFormat(src.r)
+	}
+	return format
+}
+
+// sniff identifies content from its first bytes; ZIP containers are
+// reported as FormatZIP
+func sniff(head []byte, name string) Format {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return FormatPDF
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return FormatZIP
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")):
+		return FormatOLE
+	case bytes.HasPrefix(head, []byte(`{\rtf`)):
+		return FormatRTF
+	case bytes.HasPrefix(head, []byte("\xFF\xD8\xFF")):
+		return FormatJPEG
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1A\n")):
+		return FormatPNG
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return FormatGIF
+	case bytes.HasPrefix(head, []byte("BM")) && len(head) >= 26 && !looksLikeText(head):
+		return FormatBMP
+	case bytes.HasPrefix(head, []byte("II*\x00")), bytes.HasPrefix(head, []byte("MM\x00*")):
+		return FormatTIFF
+	case bytes.HasPrefix(head, []byte("\x1F\x8B")):
+		return FormatGZIP
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return FormatTAR
+	}
+
+	if !looksLikeText(head) {
+		return FormatUnknown
+	}
+	if bytes.HasPrefix(head, []byte("From ")) {
+		if i := bytes.IndexByte(head, '\n'); i > 0 && looksLikeMail(head[i+1:]) {
+			return FormatMBOX
+		}
+	}
+	if looksLikeMail(head) {
+		return FormatEML
+	}
+	switch delimiterFormat(head) {
+	case FormatTSV:
+		return FormatTSV
+	case FormatCSV:
+		return FormatCSV
+	}
+	// Too little structure to tell (one line, ragged rows): trust the name
+	switch ext {
+	case ".csv":
+		return FormatCSV
+	case ".tsv":
+		return FormatTSV
+	}
+	return FormatText
+}
+
+// looksLikeText accepts UTF-8 and UTF-16 (with BOM) text without binary
+// control bytes
+func looksLikeText(head []byte) bool {
+	if len(head) == 0 {
+		return true // Empty files are empty text
+	}
+	if bytes.HasPrefix(head, []byte("\xFF\xFE")) || bytes.HasPrefix(head, []byte("\xFE\xFF")) {
+		return true
+	}
+	// The sniffed prefix may end inside a multi-byte rune
+	for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+	control := 0
+	for _, b := range head {
+		if b == 0 {
+			return false
+		}
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' {
+			control++
+		}
+	}
+	return control*100 < len(head) // under 1% stray control bytes
+}
+
+// delimiterFormat recognizes CSV/TSV by a delimiter that splits the first
+// lines into the same number (at least two) of fields
+func delimiterFormat(head []byte) Format {
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+	if len(lines) > 1 && len(head) == sniffLen {
+		lines = lines[:len(lines)-1] // Last line may be cut off
+	}
+	var sample []string
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			sample = append(sample, l)
+		}
+		if len(sample) == 20 {
+			break
+		}
+	}
+	if len(sample) < 2 {
+		return FormatUnknown
+	}
+	for _, d := range []struct {
+		sep    string
+		format Format
+	}{{"\t", FormatTSV}, {",", FormatCSV}, {";", FormatCSV}, {"|", FormatCSV}} {
+		fields := strings.Count(sample[0], d.sep)
+		if fields == 0 {
+			continue
+		}
+		consistent := true
+		for _, l := range sample[1:] {
+			if strings.Count(l, d.sep) != fields {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return d.format
+		}
+	}
+	return FormatUnknown
+}
+
+// zipFormat tells OOXML and OpenDocument files apart from plain ZIP archives
+// by the parts they contain
+func zipFormat(r io.ReaderAt, size int64) Format {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return FormatZIP
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "word/document.xml":
+			return FormatDOCX
+		case f.Name == "xl/workbook.xml":
+			return FormatXLSX
+		case f.Name == "ppt/presentation.xml":
+			return FormatPPTX
+		case f.Name == "mimetype":
+			if rc, err := f.Open(); err == nil {
+				mime := make([]byte, 64)
+				n, _ := io.ReadFull(rc, mime)
+				rc.Close()
+				if strings.HasPrefix(string(mime[:n]), "application/vnd.oasis.opendocument.") {
+					return FormatODF
+				}
+			}
+		}
+	}
+	return FormatZIP
+}
+
+// oleFormat tells the OLE2-based formats apart by the streams at the root
+// of the compound file: the main stream of each Office application, or the
+// MAPI property streams of an Outlook message
+func oleFormat(r io.ReaderAt) Format {
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return FormatOLE
+	}
+	for _, f := range doc.File {
+		if len(f.Path) != 0 {
+			continue
+		}
+		switch {
+		case f.Name == "WordDocument":
+			return FormatDOC
+		case f.Name == "Workbook":
+			return FormatXLS
+		case f.Name == "PowerPoint Document":
+			return FormatPPT
+		case strings.HasPrefix(f.Name, "__substg1.0_"):
+			return FormatMSG
+		}
+	}
+	return FormatOLE
+}
+
+// unsupported builds the error returned for files ExtractText cannot read
+func unsupported(format Format) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
@@ -0,0 +1,342 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// MessageTextName is the member holding a message's headers and body text;
+// its attachments sit beside it under their own names
+const MessageTextName = "message.txt"
+
+// mailHeaders are copied into the message text, so names and addresses in
+// them are scanned with the body
+var mailHeaders = []string{"From", "To", "Cc", "Bcc", "Reply-To", "Date", "Subject"}
+
+// extractMail joins the text of a message (or every message of a mailbox)
+// and of the attachments ExtractText can read, each under a heading naming
+// where it came from. Scans walk mail with WalkArchive instead, so findings
+// are attributed to the message or attachment.
+func extractMail(src source, format Format) (string, error) {
+	var sb strings.Builder
+	err := walkSource(src, format, ArchiveLimits{}, func(m Member) error {
+		doc, err := ExtractMember(m)
+		if err != nil {
+			return nil // Unreadable attachments are left out of the text
+		}
+		fmt.Fprintf(&sb, "=== %s ===\n%s\n\n", strings.TrimPrefix(m.Path, src.name+ArchiveSeparator), strings.TrimSpace(doc.Text))
+		return nil
+	})
+	if err != nil && sb.Len() == 0 {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// walkEML passes an RFC 822 message's text and attachments to fn
+func (w *archiveWalker) walkEML(container string, r io.Reader, depth int) error {
+	return w.walkMessage(container, "", r, depth)
+}
+
+// walkMessage reads one message whose parts are named under dir
+func (w *archiveWalker) walkMessage(container, dir string, r io.Reader, depth int) error {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+	return w.message(container, dir, textproto.MIMEHeader(msg.Header), msg.Body, depth)
+}
+
+// walkMBOX splits a Unix mailbox into messages, each a folder of the
+// container: "Inbox.mbox!/message-12/message.txt". A message larger than
+// MaxMemberBytes is reported and skipped; the ones after it are still read.
+func (w *archiveWalker) walkMBOX(container string, r io.Reader, depth int) error {
+	br := bufio.NewReader(r)
+	var msg bytes.Buffer
+	count := 0
+	skipping := false // rest of an oversized message
+	flush := func() error {
+		if msg.Len() == 0 {
+			return nil
+		}
+		count++
+		dir := fmt.Sprintf("message-%d", count)
+		err := w.walkMessage(container, dir, bytes.NewReader(msg.Bytes()), depth)
+		msg.Reset()
+		var stop errStop
+		if err != nil && !errors.As(err, &stop) {
+			// One bad message does not end the mailbox
+			return w.emit(Member{Path: join(container, dir), Format: FormatEML, Err: err})
+		}
+		return err
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if bytes.HasPrefix(line, []byte("From ")) {
+			if err := flush(); err != nil {
+				return err
+			}
+			skipping = false
+			line = nil // The envelope line is not part of the message
+		} else if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			line = line[1:] // mboxrd quoting: ">From " in a body was "From "
+		}
+		if !skipping && int64(msg.Len()+len(line)) > w.limits.MaxMemberBytes {
+			count++
+			msg.Reset()
+			skipping = true
+			tooLarge := fmt.Errorf("%w: larger than %d bytes", ErrArchiveLimit, w.limits.MaxMemberBytes)
+			if err := w.emit(Member{Path: join(container, fmt.Sprintf("message-%d", count)), Format: FormatEML, Err: tooLarge}); err != nil {
+				return err
+			}
+		}
+		if !skipping {
+			msg.Write(line)
+		}
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// message emits each attachment by file name, then the message's text
+// (selected headers and body) as MessageTextName, all under dir
+func (w *archiveWalker) message(container, dir string, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	var text strings.Builder
+	for _, key := range mailHeaders {
+		if v := header.Get(key); v != "" {
+			fmt.Fprintf(&text, "%s: %s\n", key, decodeHeader(v))
+		}
+	}
+	text.WriteString("\n")
+
+	attachments := 0
+	if err := w.mimePart(container, dir, header, body, &text, &attachments, depth); err != nil {
+		return err
+	}
+	return w.messageText(join(container, path.Join(dir, MessageTextName)), text.String())
+}
+
+// messageText emits the text gathered from a message. It starts with
+// headers, so it is passed on as plain text rather than re-detected as mail.
+func (w *archiveWalker) messageText(name, text string) error {
+	data, err := w.read(strings.NewReader(text), -1)
+	if err != nil {
+		return w.member(name, nil, err, 0)
+	}
+	return w.emit(Member{Path: name, Format: FormatText, Data: data})
+}
+
+// mimePart appends the text of one MIME entity to text and emits it if it is
+// an attachment. Multipart entities are walked part by part; of the
+// alternatives in multipart/alternative only the plain text one is kept when
+// there is one.
+func (w *archiveWalker) mimePart(container, dir string, header textproto.MIMEHeader, body io.Reader, text *strings.Builder, attachments *int, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := decodeHeader(dispParams["filename"])
+	if filename == "" {
+		filename = decodeHeader(params["name"])
+	}
+	body = transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		plainSeen := false
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if mediaType == "multipart/alternative" && partType == "text/html" && plainSeen {
+				continue
+			}
+			plainSeen = plainSeen || partType == "text/plain" || partType == ""
+			if err := w.mimePart(container, dir, part.Header, part, text, attachments, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if isText && disposition != "attachment" && filename == "" {
+		data, err := io.ReadAll(io.LimitReader(body, w.limits.MaxMemberBytes+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) > w.limits.MaxMemberBytes {
+			// The start is still scanned; the message is listed for review
+			data = data[:w.limits.MaxMemberBytes]
+			partial := fmt.Errorf("%w: body text larger than %d bytes, only the start was scanned", ErrArchiveLimit, w.limits.MaxMemberBytes)
+			if err := w.emit(Member{Path: join(container, path.Join(dir, MessageTextName)), Format: FormatText, Err: partial}); err != nil {
+				return err
+			}
+		}
+		s := decodeCharset(data, params["charset"])
+		if mediaType == "text/html" {
+			s = htmlToText(s)
+		}
+		text.WriteString(s)
+		text.WriteString("\n")
+		return nil
+	}
+
+	*attachments++
+	if filename == "" {
+		filename = fmt.Sprintf("attachment-%d", *attachments)
+		if mediaType == "message/rfc822" {
+			filename += ".eml"
+		} else if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			filename += exts[0]
+		}
+	}
+	data, err := w.read(body, -1)
+	return w.member(join(container, path.Join(dir, filename)), data, err, depth)
+}
+
+// transferDecoder undoes a Content-Transfer-Encoding. multipart.Reader has
+// already removed quoted-printable from parts, but not from top-level bodies.
+func transferDecoder(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// base64Cleaner drops the line breaks and stray whitespace mail clients put
+// in base64 bodies, which encoding/base64 would reject
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	for {
+		n, err := c.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// decodeCharset converts text in a declared charset to UTF-8; unknown
+// charsets are passed through
+func decodeCharset(data []byte, charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "us-ascii" {
+		return string(data)
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+var headerDecoder = mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+// decodeHeader decodes RFC 2047 encoded words ("=?utf-8?q?...?=")
+func decodeHeader(s string) string {
+	decoded, err := headerDecoder.DecodeHeader(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+var (
+	htmlHidden  = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)\s*>`)
+	htmlBreak   = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/li|/h[1-6]|/table)\b[^>]*>`)
+	htmlCell    = regexp.MustCompile(`(?i)</t[dh]\s*>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines  = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+	lineSpacing = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlToText keeps the visible text of an HTML body, with block elements on
+// their own lines and table cells tab-separated
+func htmlToText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlCell.ReplaceAllString(s, "\t")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, " ", " ")
+	s = lineSpacing.ReplaceAllStringFunc(s, func(ws string) string {
+		if strings.Contains(ws, "\t") {
+			return "\t"
+		}
+		return " "
+	})
+	return strings.TrimSpace(blankLines.ReplaceAllString(s, "\n\n"))
+}
+
+// looksLikeMail recognizes an RFC 822 header block: the first lines are
+// "Name: value" headers, at least two of them ones every message carries
+func looksLikeMail(head []byte) bool {
+	known := 0
+	for _, line := range strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n") {
+		if line == "" {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			continue // Folded continuation of the previous header
+		}
+		i := strings.IndexByte(line, ':')
+		if i <= 0 || strings.ContainsAny(line[:i], " \t") {
+			return false
+		}
+		switch strings.ToLower(line[:i]) {
+		case "from", "to", "subject", "date", "message-id", "received", "return-path", "mime-version":
+			known++
+		}
+	}
+	return known >= 2
+}
@@ -0,0 +1,216 @@
+package content
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Outlook .msg files store each MAPI property of the message, its recipients
+// and its attachments as a stream named "__substg1.0_" + property tag, where
+// the tag is the property ID followed by its type.
+const (
+	msgStreamPrefix     = "__substg1.0_"
+	msgRecipientPrefix  = "__recip_version1.0_"
+	msgAttachmentPrefix = "__attach_version1.0_"
+
+	propSubject      = "0037"
+	propSenderName   = "0C1A"
+	propSenderEmail  = "0C1F"
+	propSenderSMTP   = "5D01"
+	propDisplayTo    = "0E04"
+	propDisplayCc    = "0E03"
+	propBody         = "1000"
+	propBodyHTML     = "1013"
+	propDisplayName  = "3001"
+	propEmailAddress = "3003"
+	propSMTPAddress  = "39FE"
+	propAttachData   = "3701"
+	propAttachName   = "3704"
+	propAttachLong   = "3707"
+
+	typeString8  = "001E"
+	typeUnicode  = "001F"
+	typeBinary   = "0102"
+	typeEmbedded = "000D" // Attached .msg, stored as a sub-storage
+)
+
+// msgStorage is the streams of one storage (the message, a recipient, an
+// attachment) keyed by name, plus its child storages
+type msgStorage struct {
+	streams   map[string][]byte
+	oversized []string // streams left unread for exceeding MaxMemberBytes
+	children  map[string]*msgStorage
+	order     []string // child storage names in directory order
+}
+
+func newMsgStorage() *msgStorage {
+	return &msgStorage{streams: map[string][]byte{}, children: map[string]*msgStorage{}}
+}
+
+func (s *msgStorage) isOversized(name string) bool {
+	for _, o := range s.oversized {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// walkMSG passes an Outlook message's text and attachments to fn. Every
+// stream counts toward MaxTotalBytes; one larger than MaxMemberBytes is left
+// out and reported, while the rest of the message is still read.
+func (w *archiveWalker) walkMSG(container string, r io.ReaderAt, depth int) error {
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return err
+	}
+	root := newMsgStorage()
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		parent := root
+		for _, name := range entry.Path {
+			parent = parent.child(name)
+		}
+		if entry.FileInfo().IsDir() {
+			parent.child(entry.Name)
+			continue
+		}
+		if entry.Size > w.limits.MaxMemberBytes {
+			parent.oversized = append(parent.oversized, entry.Name)
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(entry, entry.Size))
+		if err != nil {
+			return err
+		}
+		if err := w.account(int64(len(data))); err != nil {
+			return err
+		}
+		parent.streams[entry.Name] = data
+	}
+	return w.msgMessage(container, root, depth)
+}
+
+func (s *msgStorage) child(name string) *msgStorage {
+	c, ok := s.children[name]
+	if !ok {
+		c = newMsgStorage()
+		s.children[name] = c
+		s.order = append(s.order, name)
+	}
+	return c
+}
+
+// msgMessage emits one message storage like message does for MIME: the
+// header fields and body as MessageTextName, then the attachments
+func (w *archiveWalker) msgMessage(container string, m *msgStorage, depth int) error {
+	var text strings.Builder
+	from := m.text(propSenderName)
+	if addr := firstNonEmpty(m.text(propSenderSMTP), m.text(propSenderEmail)); addr != "" {
+		from = strings.TrimSpace(fmt.Sprintf("%s <%s>", from, addr))
+	}
+	var recipients []string
+	for _, name := range m.order {
+		if strings.HasPrefix(name, msgRecipientPrefix) {
+			r := m.children[name]
+			recipient := r.text(propDisplayName)
+			if addr := firstNonEmpty(r.text(propSMTPAddress), r.text(propEmailAddress)); addr != "" {
+				recipient = strings.TrimSpace(fmt.Sprintf("%s <%s>", recipient, addr))
+			}
+			recipients = append(recipients, recipient)
+		}
+	}
+	for _, field := range []struct{ key, value string }{
+		{"From", from},
+		{"To", m.text(propDisplayTo)},
+		{"Cc", m.text(propDisplayCc)},
+		{"Recipients", strings.Join(recipients, "; ")},
+		{"Subject", m.text(propSubject)},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&text, "%s: %s\n", field.key, field.value)
+		}
+	}
+	text.WriteString("\n")
+	if body := m.text(propBody); body != "" {
+		text.WriteString(body)
+	} else if body := m.text(propBodyHTML); body != "" {
+		text.WriteString(htmlToText(body))
+	}
+	text.WriteString("\n")
+
+	if err := w.messageText(join(container, MessageTextName), text.String()); err != nil {
+		return err
+	}
+	for _, name := range m.oversized {
+		// A property left out of the text, such as a huge body
+		err := fmt.Errorf("%w: property stream %s larger than %d bytes", ErrArchiveLimit, name, w.limits.MaxMemberBytes)
+		if err := w.emit(Member{Path: join(container, MessageTextName), Format: FormatText, Err: err}); err != nil {
+			return err
+		}
+	}
+
+	count := 0
+	for _, name := range m.order {
+		if !strings.HasPrefix(name, msgAttachmentPrefix) {
+			continue
+		}
+		count++
+		a := m.children[name]
+		filename := firstNonEmpty(a.text(propAttachLong), a.text(propAttachName), a.text(propDisplayName))
+		if filename == "" {
+			filename = fmt.Sprintf("attachment-%d", count)
+		}
+		var err error
+		dataStream := msgStreamPrefix + propAttachData + typeBinary
+		if embedded, ok := a.children[msgStreamPrefix+propAttachData+typeEmbedded]; ok {
+			// An attached message: its parts form a folder named after it
+			if depth+1 > w.limits.MaxDepth {
+				err = w.emit(Member{Path: join(container, filename), Format: FormatMSG, Err: fmt.Errorf("%w: nested deeper than %d archives", ErrArchiveLimit, w.limits.MaxDepth)})
+			} else {
+				err = w.msgMessage(join(container, filename), embedded, depth+1)
+			}
+		} else if a.isOversized(dataStream) {
+			err = w.emit(Member{Path: join(container, filename), Format: FormatUnknown, Err: fmt.Errorf("%w: larger than %d bytes", ErrArchiveLimit, w.limits.MaxMemberBytes)})
+		} else {
+			// Already read and counted toward the total by walkMSG
+			err = w.member(join(container, filename), a.streams[dataStream], w.count(), depth)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// text returns a string property stored either as UTF-16 or as 8-bit text,
+// or an HTML body stored as binary
+func (s *msgStorage) text(prop string) string {
+	if data, ok := s.streams[msgStreamPrefix+prop+typeUnicode]; ok {
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[2*i:])
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	}
+	if data, ok := s.streams[msgStreamPrefix+prop+typeString8]; ok {
+		return strings.TrimRight(string(data), "\x00")
+	}
+	if data, ok := s.streams[msgStreamPrefix+prop+typeBinary]; ok && prop == propBodyHTML {
+		return string(data)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
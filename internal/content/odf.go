@@ -0,0 +1,116 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	odfOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odfTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odfTableNS  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odfDrawNS   = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+	odfDCNS     = "http://purl.org/dc/elements/1.1/"
+)
+
+// extractODF reads content.xml of an OpenDocument text, spreadsheet or
+// presentation. Paragraphs and headings become lines, spreadsheet rows
+// tab-separated lines like extractXLSX, and each presentation page gets a
+// heading like extractPPTX. Comments and speaker notes are part of
+// content.xml and are read with the rest.
+func extractODF(src source) (string, error) {
+	zr, err := zip.NewReader(src.r, src.size)
+	if err != nil {
+		return "", err
+	}
+	if manifest, err := zipPart(zr, "META-INF/manifest.xml"); err == nil && bytes.Contains(manifest, []byte("encryption-data")) {
+		return "", fmt.Errorf("%w: password-protected OpenDocument file", ErrEncrypted)
+	}
+	data, err := zipPart(zr, "content.xml")
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	d := xml.NewDecoder(bytes.NewReader(data))
+	cells := 0 // open table cells; paragraphs inside one stay on the row's line
+	pages := 0
+	endParagraph := func() {
+		if cells > 0 {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString("\n")
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name {
+			case xml.Name{Space: odfTextNS, Local: "tab"}:
+				sb.WriteString("\t")
+			case xml.Name{Space: odfTextNS, Local: "line-break"}:
+				sb.WriteString("\n")
+			case xml.Name{Space: odfTextNS, Local: "s"}:
+				n := 1
+				for _, a := range t.Attr {
+					if a.Name.Local == "c" {
+						if c, err := strconv.Atoi(a.Value); err == nil && c > 0 && c < 1000 {
+							n = c
+						}
+					}
+				}
+				sb.WriteString(strings.Repeat(" ", n))
+			case xml.Name{Space: odfTableNS, Local: "table-cell"}, xml.Name{Space: odfTableNS, Local: "covered-table-cell"}:
+				cells++
+			case xml.Name{Space: odfOfficeNS, Local: "annotation"}:
+				endParagraph() // A comment anchored mid-paragraph starts its own line
+			case xml.Name{Space: odfDrawNS, Local: "page"}:
+				pages++
+				fmt.Fprintf(&sb, "=== Slide %d ===\n", pages)
+			}
+		case xml.EndElement:
+			switch t.Name {
+			case xml.Name{Space: odfTextNS, Local: "p"}, xml.Name{Space: odfTextNS, Local: "h"}:
+				endParagraph()
+			case xml.Name{Space: odfDCNS, Local: "creator"}, xml.Name{Space: odfDCNS, Local: "date"}:
+				sb.WriteString(" ") // Comment author and date
+			case xml.Name{Space: odfTableNS, Local: "table-cell"}, xml.Name{Space: odfTableNS, Local: "covered-table-cell"}:
+				cells--
+				sb.WriteString("\t")
+			case xml.Name{Space: odfTableNS, Local: "table-row"}:
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			sb.Write(t)
+		}
+	}
+	return odfLines(sb.String()), nil
+}
+
+// odfLines tidies the extracted text: the padding left by cell paragraphs
+// and trailing empty cells is trimmed and empty lines dropped
+func odfLines(s string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		cells := strings.Split(line, "\t")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		if len(cells) > 0 {
+			sb.WriteString(strings.Join(cells, "\t"))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
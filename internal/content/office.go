@@ -0,0 +1,666 @@
+package content
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// oleStreams reads the named streams at the root of an OLE2 compound file.
+// Streams that are not present are left out of the map.
+func oleStreams(r io.ReaderAt, names ...string) (map[string][]byte, error) {
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return nil, err
+	}
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	streams := map[string][]byte{}
+	for _, f := range doc.File {
+		if len(f.Path) != 0 || !wanted[f.Name] || f.FileInfo().IsDir() {
+			continue
+		}
+		// The declared size is not trusted: reading grows the buffer only as
+		// far as the stream's sectors actually go
+		limit := DefaultArchiveLimits().MaxMemberBytes
+		data, err := io.ReadAll(io.LimitReader(io.NewSectionReader(f, 0, f.Size), limit+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > limit {
+			return nil, fmt.Errorf("%w: stream %s larger than %d bytes", ErrArchiveLimit, f.Name, limit)
+		}
+		streams[f.Name] = data
+	}
+	return streams, nil
+}
+
+// extractOLE handles OLE2 files that are not a known Office document. An
+// OOXML file saved with a password is stored this way, as an encrypted
+// package.
+func extractOLE(src source) (string, error) {
+	streams, err := oleStreams(src.r, "EncryptedPackage")
+	if err != nil {
+		return "", err
+	}
+	if _, ok := streams["EncryptedPackage"]; ok {
+		return "", fmt.Errorf("%w: password-protected Office document", ErrEncrypted)
+	}
+	return "", unsupported(FormatOLE)
+}
+
+// u16 and u32 read little-endian integers, returning 0 past the end of b so
+// corrupt offsets cannot panic
+func u16(b []byte, off int) uint16 {
+	if off < 0 || off+2 > len(b) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b[off:])
+}
+
+func u32(b []byte, off int) uint32 {
+	if off < 0 || off+4 > len(b) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b[off:])
+}
+
+// utf16Text decodes little-endian UTF-16
+func utf16Text(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// latin1Text decodes the 8-bit strings of the binary Office formats, which
+// hold the low byte of each UTF-16 code unit
+func latin1Text(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// Word 97-2003 (.doc)
+
+const (
+	wordIdent     = 0xA5EC
+	wordNFib97    = 0x00C1 // Word 97; earlier versions use another layout
+	wordEncrypted = 0x0100 // fEncrypted
+	wordTable1    = 0x0200 // fWhichTblStm: the piece table is in "1Table"
+	wordXORed     = 0x8000 // fObfuscated
+	pieceCompact  = 0x40000000
+)
+
+// extractDOC reads the text of a Word 97-2003 document through its piece
+// table, which maps character positions to runs of 8-bit or UTF-16 text in
+// the WordDocument stream. The whole text is read: body, headers, footnotes,
+// comments and text boxes.
+func extractDOC(src source) (string, error) {
+	streams, err := oleStreams(src.r, "WordDocument", "0Table", "1Table")
+	if err != nil {
+		return "", err
+	}
+	doc := streams["WordDocument"]
+	if u16(doc, 0) != wordIdent {
+		return "", fmt.Errorf("malformed Word document: bad FIB")
+	}
+	if u16(doc, 2) < wordNFib97 {
+		return "", fmt.Errorf("%w: Word 6.0/95 document", ErrUnsupportedFormat)
+	}
+	flags := u16(doc, 0x0A)
+	if flags&(wordEncrypted|wordXORed) != 0 {
+		return "", fmt.Errorf("%w: password-protected Word document", ErrEncrypted)
+	}
+	table := streams["0Table"]
+	if flags&wordTable1 != 0 {
+		table = streams["1Table"]
+	}
+
+	// The FIB is a chain of variable-length arrays; fcClx is the 34th
+	// offset/length pair of the last one
+	off := 32
+	off += 2 + int(u16(doc, off))*2
+	off += 2 + int(u16(doc, off))*4
+	blob := off + 2
+	fcClx, lcbClx := int(u32(doc, blob+33*8)), int(u32(doc, blob+33*8+4))
+	if lcbClx == 0 || fcClx+lcbClx > len(table) {
+		return "", fmt.Errorf("malformed Word document: piece table out of range")
+	}
+	pieces, err := wordPieces(table[fcClx : fcClx+lcbClx])
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	limit := DefaultArchiveLimits().MaxMemberBytes
+	for _, p := range pieces {
+		if int64(sb.Len()+2*p.chars) > limit { // Pieces may overlap, so their total is not bounded by the stream
+			return "", fmt.Errorf("%w: text larger than %d bytes", ErrArchiveLimit, limit)
+		}
+		if p.compact {
+			end := p.fc + p.chars
+			if end > len(doc) {
+				return "", fmt.Errorf("malformed Word document: text out of range")
+			}
+			text, _ := charmap.Windows1252.NewDecoder().Bytes(doc[p.fc:end])
+			sb.Write(text)
+		} else {
+			end := p.fc + 2*p.chars
+			if end > len(doc) {
+				return "", fmt.Errorf("malformed Word document: text out of range")
+			}
+			sb.WriteString(utf16Text(doc[p.fc:end]))
+		}
+	}
+	return wordText(sb.String()), nil
+}
+
+type wordPiece struct {
+	fc      int // byte offset in the WordDocument stream
+	chars   int
+	compact bool // 8-bit Windows-1252 rather than UTF-16
+}
+
+// wordPieces parses the Clx: property modifiers to skip, then the PlcPcd of
+// n+1 character positions followed by n 8-byte piece descriptors
+func wordPieces(clx []byte) ([]wordPiece, error) {
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 { // Prc
+		cb := int(int16(u16(clx, pos+1)))
+		if cb < 0 || pos+3+cb > len(clx) {
+			return nil, fmt.Errorf("malformed Word document: bad property modifier")
+		}
+		pos += 3 + cb
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return nil, fmt.Errorf("malformed Word document: no piece table")
+	}
+	lcb := int(u32(clx, pos+1))
+	plc := clx[pos+5:]
+	if lcb < 4 || lcb > len(plc) || (lcb-4)%12 != 0 {
+		return nil, fmt.Errorf("malformed Word document: bad piece table")
+	}
+	n := (lcb - 4) / 12
+	pieces := make([]wordPiece, 0, n)
+	for i := 0; i < n; i++ {
+		start, end := int(u32(plc, 4*i)), int(u32(plc, 4*(i+1)))
+		if end < start {
+			return nil, fmt.Errorf("malformed Word document: bad piece table")
+		}
+		fc := u32(plc, 4*(n+1)+8*i+2)
+		p := wordPiece{fc: int(fc), chars: end - start}
+		if fc&pieceCompact != 0 {
+			p.fc, p.compact = int(fc&^pieceCompact)/2, true
+		}
+		pieces = append(pieces, p)
+	}
+	return pieces, nil
+}
+
+// wordText turns Word's control characters into plain text: paragraph and
+// line marks become newlines, table cell marks tabs, and of each field only
+// the displayed result is kept, not its instructions
+func wordText(s string) string {
+	var sb strings.Builder
+	var fields []bool // per open field: true once past its separator
+	for _, r := range s {
+		switch r {
+		case 0x13: // Field begin
+			fields = append(fields, false)
+			continue
+		case 0x14: // Field separator
+			if len(fields) > 0 {
+				fields[len(fields)-1] = true
+			}
+			continue
+		case 0x15: // Field end
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+		if len(fields) > 0 && !fields[len(fields)-1] {
+			continue
+		}
+		switch {
+		case r == '\r', r == 0x0B, r == 0x0C, r == 0x0E:
+			sb.WriteByte('\n')
+		case r == 0x07:
+			sb.WriteByte('\t')
+		case r == 0x1E:
+			sb.WriteByte('-')
+		case r == '\t' || r >= 0x20:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Excel 97-2003 (.xls)
+
+const (
+	biffBOF        = 0x0809
+	biffEOF        = 0x000A
+	biffFilePass   = 0x002F
+	biffDateMode   = 0x0022
+	biffBoundSheet = 0x0085
+	biffSST        = 0x00FC
+	biffContinue   = 0x003C
+	biffFormat     = 0x041E
+	biffXF         = 0x00E0
+	biffLabelSST   = 0x00FD
+	biffLabel      = 0x0204
+	biffNumber     = 0x0203
+	biffRK         = 0x027E
+	biffMulRK      = 0x00BD
+	biffBoolErr    = 0x0205
+	biffFormula    = 0x0006
+	biffFormulaStr = 0x0207
+
+	biff8     = 0x0600
+	xlsMaxRow = 65536
+	xlsMaxCol = 256
+)
+
+type biffRecord struct {
+	offset int
+	typ    uint16
+	data   []byte
+}
+
+// xlsSheet collects the cells of one worksheet
+type xlsSheet struct {
+	name string
+	rows [][]string
+}
+
+func (s *xlsSheet) set(row, col int, value string) {
+	if s == nil || row < 0 || row >= xlsMaxRow || col >= xlsMaxCol || value == "" {
+		return
+	}
+	for len(s.rows) <= row {
+		s.rows = append(s.rows, nil)
+	}
+	for len(s.rows[row]) <= col {
+		s.rows[row] = append(s.rows[row], "")
+	}
+	s.rows[row][col] = value
+}
+
+// extractXLS reads the cells of every worksheet of an Excel 97-2003
+// workbook, one tab-separated line per row like extractXLSX. Numbers in
+// cells formatted as dates are written as dates.
+func extractXLS(src source) (string, error) {
+	streams, err := oleStreams(src.r, "Workbook")
+	if err != nil {
+		return "", err
+	}
+	stream := streams["Workbook"]
+	if u16(stream, 0) != biffBOF || u16(stream, 4) != biff8 {
+		return "", fmt.Errorf("%w: Excel 5.0/95 workbook", ErrUnsupportedFormat)
+	}
+
+	var records []biffRecord
+	for pos := 0; pos+4 <= len(stream); {
+		typ, size := u16(stream, pos), int(u16(stream, pos+2))
+		if pos+4+size > len(stream) {
+			break
+		}
+		records = append(records, biffRecord{offset: pos, typ: typ, data: stream[pos+4 : pos+4+size]})
+		pos += 4 + size
+	}
+
+	var (
+		sheets    []*xlsSheet
+		sheetAt   = map[int]*xlsSheet{} // by offset of the sheet's BOF
+		sst       []string
+		formats   = map[uint16]string{}
+		xfFormats []uint16
+		date1904  bool
+		current   *xlsSheet
+		pending   = [2]int{-1, -1} // cell awaiting a formula's STRING record
+		outer     []*xlsSheet      // sheets interrupted by embedded chart substreams
+	)
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		d := rec.data
+		switch rec.typ {
+		case biffFilePass:
+			return "", fmt.Errorf("%w: password-protected Excel workbook", ErrEncrypted)
+		case biffDateMode:
+			date1904 = u16(d, 0) == 1
+		case biffBoundSheet:
+			if len(d) > 6 && d[5] == 0 { // Worksheets only, not charts or macros
+				sheet := &xlsSheet{name: biffString(d[6:], true)}
+				sheets = append(sheets, sheet)
+				sheetAt[int(u32(d, 0))] = sheet
+			}
+		case biffFormat:
+			if len(d) > 2 {
+				formats[u16(d, 0)] = biffString(d[2:], false)
+			}
+		case biffXF:
+			xfFormats = append(xfFormats, u16(d, 2))
+		case biffSST:
+			segments := [][]byte{d}
+			for i+1 < len(records) && records[i+1].typ == biffContinue {
+				i++
+				segments = append(segments, records[i].data)
+			}
+			sst = readSST(segments)
+		case biffBOF:
+			outer = append(outer, current)
+			current = sheetAt[rec.offset]
+			continue
+		case biffEOF:
+			if len(outer) > 0 {
+				current, outer = outer[len(outer)-1], outer[:len(outer)-1]
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		row, col, xf := int(u16(d, 0)), int(u16(d, 2)), int(u16(d, 4))
+		number := func(v float64) string {
+			if xf < len(xfFormats) && isDateFormat(xfFormats[xf], formats) {
+				return excelDate(v, date1904)
+			}
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		switch rec.typ {
+		case biffLabelSST:
+			if isst := int(u32(d, 6)); isst < len(sst) {
+				current.set(row, col, sst[isst])
+			}
+		case biffLabel:
+			if len(d) > 6 {
+				current.set(row, col, biffString(d[6:], false))
+			}
+		case biffNumber:
+			if len(d) >= 14 {
+				current.set(row, col, number(math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))))
+			}
+		case biffRK:
+			current.set(row, col, number(rkValue(u32(d, 6))))
+		case biffMulRK:
+			for j := 0; 4+6*j+6 <= len(d)-2; j++ {
+				xf = int(u16(d, 4+6*j))
+				current.set(row, col+j, number(rkValue(u32(d, 4+6*j+2))))
+			}
+		case biffBoolErr:
+			if len(d) >= 8 && d[7] == 0 {
+				current.set(row, col, strconv.FormatBool(d[6] != 0))
+			}
+		case biffFormula:
+			if len(d) < 14 {
+				continue
+			}
+			if u16(d, 12) != 0xFFFF {
+				current.set(row, col, number(math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))))
+			} else if d[6] == 0 { // String result, in the next STRING record
+				pending = [2]int{row, col}
+			} else if d[6] == 1 {
+				current.set(row, col, strconv.FormatBool(d[8] != 0))
+			}
+		case biffFormulaStr:
+			current.set(pending[0], pending[1], biffString(d, false))
+			pending = [2]int{-1, -1}
+		}
+	}
+
+	var sb strings.Builder
+	for _, sheet := range sheets {
+		for _, row := range sheet.rows {
+			sb.WriteString(strings.Join(row, "\t"))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// biffString reads an XLUnicodeString (16-bit length) or, if short, a
+// ShortXLUnicodeString (8-bit length)
+func biffString(b []byte, short bool) string {
+	var cch, pos int
+	if short {
+		if len(b) < 2 {
+			return ""
+		}
+		cch, pos = int(b[0]), 1
+	} else {
+		if len(b) < 3 {
+			return ""
+		}
+		cch, pos = int(u16(b, 0)), 2
+	}
+	high := b[pos]&0x01 != 0
+	pos++
+	width := 1
+	if high {
+		width = 2
+	}
+	end := pos + cch*width
+	if end > len(b) {
+		end = len(b) - (len(b)-pos)%width
+	}
+	if high {
+		return utf16Text(b[pos:end])
+	}
+	return latin1Text(b[pos:end])
+}
+
+// sstReader reads the shared string table, which runs on into CONTINUE
+// records. A string split across records restarts with a fresh flags byte
+// saying whether its remaining characters are 8 or 16 bits wide.
+type sstReader struct {
+	segments [][]byte
+	seg, pos int
+}
+
+func (r *sstReader) atEnd() bool {
+	for r.seg < len(r.segments) && r.pos >= len(r.segments[r.seg]) {
+		r.seg++
+		r.pos = 0
+	}
+	return r.seg >= len(r.segments)
+}
+
+func (r *sstReader) bytes(n int) []byte {
+	var out []byte
+	for n > 0 && !r.atEnd() {
+		seg := r.segments[r.seg]
+		take := min(n, len(seg)-r.pos)
+		out = append(out, seg[r.pos:r.pos+take]...)
+		r.pos += take
+		n -= take
+	}
+	return out
+}
+
+func (r *sstReader) chars(n int, high bool) string {
+	var sb strings.Builder
+	for n > 0 && !r.atEnd() {
+		if r.pos == 0 && r.seg > 0 {
+			high = r.segments[r.seg][0]&0x01 != 0
+			r.pos = 1
+			continue
+		}
+		width := 1
+		if high {
+			width = 2
+		}
+		seg := r.segments[r.seg]
+		take := min(n, (len(seg)-r.pos)/width)
+		if take == 0 {
+			r.pos = len(seg)
+			continue
+		}
+		b := seg[r.pos : r.pos+take*width]
+		if high {
+			sb.WriteString(utf16Text(b))
+		} else {
+			sb.WriteString(latin1Text(b))
+		}
+		r.pos += take * width
+		n -= take
+	}
+	return sb.String()
+}
+
+func readSST(segments [][]byte) []string {
+	r := &sstReader{segments: segments}
+	header := r.bytes(8)
+	unique := int(u32(header, 4))
+	strs := make([]string, 0, min(unique, 1<<16))
+	for i := 0; i < unique && !r.atEnd(); i++ {
+		head := r.bytes(3)
+		if len(head) < 3 {
+			break
+		}
+		cch, flags := int(u16(head, 0)), head[2]
+		runs, ext := 0, 0
+		if flags&0x08 != 0 { // fRichSt
+			runs = int(u16(r.bytes(2), 0))
+		}
+		if flags&0x04 != 0 { // fExtSt
+			ext = int(u32(r.bytes(4), 0))
+		}
+		strs = append(strs, r.chars(cch, flags&0x01 != 0))
+		r.bytes(4*runs + ext)
+	}
+	return strs
+}
+
+// rkValue decodes an RK number: a 30-bit integer or the top of a double,
+// optionally scaled by 100
+func rkValue(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// isDateFormat reports whether a number format displays a date: one of the
+// built-in date or date-time formats, or a custom one with day or year codes outside
+// quoted literals
+func isDateFormat(ifmt uint16, formats map[uint16]string) bool {
+	if (ifmt >= 14 && ifmt <= 17) || ifmt == 22 {
+		return true
+	}
+	code, ok := formats[ifmt]
+	if !ok {
+		return false
+	}
+	code = strings.ToLower(code)
+	var sb strings.Builder
+	quoted, bracket := false, false
+	for _, r := range code {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '[' && !quoted:
+			bracket = true
+		case r == ']' && !quoted:
+			bracket = false
+		case !quoted && !bracket:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.ContainsAny(sb.String(), "dy")
+}
+
+// excelDate converts a serial date to ISO form, with the time if it has one
+func excelDate(serial float64, date1904 bool) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	t := epoch.Add(time.Duration(math.Round(serial*86400)) * time.Second)
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// PowerPoint 97-2003 (.ppt)
+
+const (
+	pptContainer        = 0x000F // recVer of records holding other records
+	pptMainMaster       = 0x03F8
+	pptTextChars        = 0x0FA0
+	pptTextBytes        = 0x0FA8
+	pptCString          = 0x0FBA
+	pptCryptSession     = 0x2F14
+	pptEncryptedSummary = "EncryptedSummary"
+)
+
+// extractPPT collects the text atoms of a PowerPoint 97-2003 presentation:
+// slide and notes text, text boxes and comments. The placeholder prompts of
+// slide masters are left out.
+func extractPPT(src source) (string, error) {
+	streams, err := oleStreams(src.r, "PowerPoint Document", pptEncryptedSummary)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := streams[pptEncryptedSummary]; ok {
+		return "", fmt.Errorf("%w: password-protected PowerPoint presentation", ErrEncrypted)
+	}
+	return pptStreamText(streams["PowerPoint Document"])
+}
+
+// pptStreamText walks the record tree of the PowerPoint Document stream
+func pptStreamText(stream []byte) (string, error) {
+	var sb strings.Builder
+	for pos := 0; pos+8 <= len(stream); {
+		verInst, typ, size := u16(stream, pos), u16(stream, pos+2), int(u32(stream, pos+4))
+		body := pos + 8
+		if body+size > len(stream) {
+			break
+		}
+		switch {
+		case typ == pptCryptSession:
+			return "", fmt.Errorf("%w: password-protected PowerPoint presentation", ErrEncrypted)
+		case typ == pptMainMaster:
+			pos = body + size
+			continue
+		case verInst&0x000F == pptContainer:
+			pos = body // Descend into the container's records
+			continue
+		case typ == pptTextChars, typ == pptCString:
+			sb.WriteString(pptText(utf16Text(stream[body : body+size])))
+		case typ == pptTextBytes:
+			sb.WriteString(pptText(latin1Text(stream[body : body+size])))
+		}
+		pos = body + size
+	}
+	return sb.String(), nil
+}
+
+// pptText puts one text atom on its own lines; PowerPoint ends paragraphs
+// with carriage returns and breaks lines with vertical tabs
+func pptText(s string) string {
+	s = strings.NewReplacer("\r", "\n", "\v", "\n").Replace(s)
+	return strings.TrimSpace(s) + "\n"
+}
@@ -0,0 +1,200 @@
+package content
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// le builds little-endian test input from 8-, 16- and 32-bit values
+func le(values ...any) []byte {
+	var b []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case uint8:
+			b = append(b, v)
+		case uint16:
+			b = binary.LittleEndian.AppendUint16(b, v)
+		case uint32:
+			b = binary.LittleEndian.AppendUint32(b, v)
+		case []byte:
+			b = append(b, v...)
+		case string:
+			b = append(b, v...)
+		}
+	}
+	return b
+}
+
+// onePieceClx is a Clx with a 2-byte property modifier and one compact piece
+// of 5 characters at byte 0x400
+var onePieceClx = le(uint8(0x01), uint16(2), uint16(0),
+	uint8(0x02), uint32(16), uint32(0), uint32(5), uint16(0), uint32(pieceCompact|0x800), uint16(0))
+
+func TestWordPieces(t *testing.T) {
+	tests := []struct {
+		name string
+		clx  []byte
+		want []wordPiece
+		ok   bool
+	}{
+		{"one piece", onePieceClx, []wordPiece{{fc: 0x400, chars: 5, compact: true}}, true},
+		{"utf-16 piece", le(uint8(0x02), uint32(16), uint32(2), uint32(9), uint16(0), uint32(0x200), uint16(0)), []wordPiece{{fc: 0x200, chars: 7}}, true},
+		{"empty", nil, nil, false},
+		{"negative modifier size", le(uint8(0x01), uint16(0xFFF0), uint8(0xFF), uint8(0x02), make([]byte, 16)), nil, false},
+		{"modifier size looping back", le(uint8(0x01), uint16(0xFFFD), uint8(0x02)), nil, false},
+		{"modifier past the end", le(uint8(0x01), uint16(100), uint8(0x02)), nil, false},
+		{"truncated piece table header", le(uint8(0x02), uint8(16)), nil, false},
+		{"piece table longer than data", le(uint8(0x02), uint32(28), uint32(0), uint32(5)), nil, false},
+		{"character positions out of order", le(uint8(0x02), uint32(16), uint32(5), uint32(0), uint16(0), uint32(0), uint16(0)), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wordPieces(tt.clx)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok = %v", err, tt.ok)
+			}
+			if tt.ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzWordPieces(f *testing.F) {
+	f.Add(onePieceClx)
+	f.Add(le(uint8(0x01), uint16(0xFFF0), uint8(0xFF), uint8(0x02)))
+	f.Add(le(uint8(0x01), uint16(0xFFFD), uint8(0x02)))
+	f.Fuzz(func(t *testing.T, clx []byte) {
+		pieces, err := wordPieces(clx)
+		if err == nil && len(pieces) > len(clx)/12 {
+			t.Errorf("%d pieces from %d bytes", len(pieces), len(clx))
+		}
+	})
+}
+
+func TestReadSST(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments [][]byte
+		want     []string
+	}{
+		{
+			"8-bit and 16-bit strings",
+			[][]byte{le(uint32(2), uint32(2), uint16(3), uint8(0), "Ann", uint16(2), uint8(1), uint16('J'), uint16('o'))},
+			[]string{"Ann", "Jo"},
+		},
+		{
+			"string continued in 16 bits",
+			[][]byte{le(uint32(1), uint32(1), uint16(5), uint8(0), "Ma"), le(uint8(1), uint16('r'), uint16('í'), uint16('a'))},
+			[]string{"María"},
+		},
+		{
+			"rich text runs skipped",
+			[][]byte{le(uint32(2), uint32(2), uint16(2), uint8(0x08), uint16(1), "Al", uint32(0), uint16(1), uint8(0), "B")},
+			[]string{"Al", "B"},
+		},
+		{
+			"count larger than data",
+			[][]byte{le(uint32(9), uint32(1000000), uint16(1), uint8(0), "X")},
+			[]string{"X"},
+		},
+		{"truncated header", [][]byte{{0x01, 0x00}}, []string{}},
+		{"huge extension block", [][]byte{le(uint32(1), uint32(1), uint16(1), uint8(0x04), uint32(0xFFFFFFFF), "Z")}, []string{"Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readSST(tt.segments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzReadSST(f *testing.F) {
+	f.Add(le(uint32(2), uint32(2), uint16(3), uint8(0), "Ann", uint16(2), uint8(1), uint16('J'), uint16('o')), []byte{1, 'x', 0})
+	f.Add(le(uint32(1), uint32(1), uint16(0xFFFF), uint8(0x0D), uint16(0xFFFF), uint32(0xFFFFFFFF)), []byte{})
+	f.Fuzz(func(t *testing.T, first, cont []byte) {
+		readSST([][]byte{first, cont})
+	})
+}
+
+func TestBiffString(t *testing.T) {
+	tests := []struct {
+		name  string
+		b     []byte
+		short bool
+		want  string
+	}{
+		{"8-bit", le(uint16(3), uint8(0), "Bob"), false, "Bob"},
+		{"16-bit", le(uint16(2), uint8(1), uint16('J'), uint16('ö')), false, "Jö"},
+		{"short 8-bit", le(uint8(6), uint8(0), "Sheet1"), true, "Sheet1"},
+		{"count past the end", le(uint16(10), uint8(0), "Bo"), false, "Bo"},
+		{"odd 16-bit tail", le(uint16(4), uint8(1), uint16('A'), uint8('B')), false, "A"},
+		{"too short", []byte{1, 0}, false, ""},
+		{"short too short", []byte{1}, true, ""},
+		{"empty", nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := biffString(tt.b, tt.short); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzBiffString(f *testing.F) {
+	f.Add(le(uint16(3), uint8(0), "Bob"), false)
+	f.Add(le(uint16(0xFFFF), uint8(1), uint8('A')), false)
+	f.Add(le(uint8(6), uint8(1), "Sheet1"), true)
+	f.Fuzz(func(t *testing.T, b []byte, short bool) {
+		biffString(b, short)
+	})
+}
+
+// pptRecord builds a PowerPoint record header and body
+func pptRecord(verInst, typ uint16, body []byte) []byte {
+	return le(verInst, typ, uint32(len(body)), body)
+}
+
+func TestPPTStreamText(t *testing.T) {
+	slide := pptRecord(0x000F, 0x03EE, append(
+		pptRecord(0, pptTextBytes, []byte("Patient: Jane Roe\rMRN 12345")),
+		pptRecord(0, pptTextChars, le(uint16('H'), uint16('i'), uint16('\v'), uint16('!')))...))
+	master := pptRecord(0x000F, pptMainMaster, pptRecord(0, pptTextBytes, []byte("Click to edit")))
+
+	tests := []struct {
+		name    string
+		stream  []byte
+		want    string
+		wantErr error
+	}{
+		{"slide text", slide, "Patient: Jane Roe\nMRN 12345\nHi\n!\n", nil},
+		{"master skipped", append(master, slide...), "Patient: Jane Roe\nMRN 12345\nHi\n!\n", nil},
+		{"encrypted", pptRecord(0, pptCryptSession, nil), "", ErrEncrypted},
+		{"record past the end", le(uint16(0), uint16(pptTextBytes), uint32(0xFFFFFFFF), "abc"), "", nil},
+		{"truncated header", []byte{0x0F, 0x00, 0xA8}, "", nil},
+		{"empty", nil, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pptStreamText(tt.stream)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzPPTStreamText(f *testing.F) {
+	f.Add(pptRecord(0x000F, 0x03EE, pptRecord(0, pptTextBytes, []byte("Jane Roe"))))
+	f.Add(le(uint16(0x000F), uint16(0), uint32(0xFFFFFFF8)))
+	f.Fuzz(func(t *testing.T, stream []byte) {
+		pptStreamText(stream)
+	})
+}
@@ -0,0 +1,398 @@
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// zipPart reads one part of an OOXML or OpenDocument package. A missing
+// part is reported as fs.ErrNotExist. The package is an archive, so a part is
+// held to the same size and compression ratio limits as an archive member.
+func zipPart(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	limits := DefaultArchiveLimits()
+	limit := limits.MaxMemberBytes
+	if info, err := f.Stat(); err == nil {
+		if h, ok := info.Sys().(*zip.FileHeader); ok {
+			limit = min(limit, max(int64(h.CompressedSize64)*limits.MaxRatio, ratioFloor))
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s expands to more than %d bytes", ErrArchiveLimit, name, limit)
+	}
+	return data, nil
+}
+
+// relationship is one entry of an OOXML part's .rels file
+type relationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+	Mode   string `xml:"TargetMode,attr"`
+}
+
+// partRels reads the relationships of part, with targets resolved to part
+// names. External targets (hyperlinks) are left out.
+func partRels(zr *zip.Reader, part string) []relationship {
+	data, err := zipPart(zr, path.Join(path.Dir(part), "_rels", path.Base(part)+".rels"))
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		Rels []relationship `xml:"Relationship"`
+	}
+	if xml.Unmarshal(data, &doc) != nil {
+		return nil
+	}
+	var rels []relationship
+	for _, r := range doc.Rels {
+		if r.Mode == "External" {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			r.Target = strings.TrimPrefix(r.Target, "/")
+		} else {
+			r.Target = path.Join(path.Dir(part), r.Target)
+		}
+		rels = append(rels, r)
+	}
+	return rels
+}
+
+// relsOfType returns the targets of part's relationships whose type ends in
+// "/"+kind, e.g. "notesSlide"
+func relsOfType(rels []relationship, kind string) []string {
+	var targets []string
+	for _, r := range rels {
+		if strings.HasSuffix(r.Type, "/"+kind) {
+			targets = append(targets, r.Target)
+		}
+	}
+	return targets
+}
+
+// drawingText returns the text of a DrawingML or PresentationML part: the
+// runs of each paragraph on one line. Comment text (legacy <p:text>) is read
+// the same way.
+func drawingText(data []byte) string {
+	var sb strings.Builder
+	d := xml.NewDecoder(bytes.NewReader(data))
+	inText := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t", "text":
+				inText++
+			case "br":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t", "text":
+				inText--
+			case "p", "cm":
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText > 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return collapseBlankLines(sb.String())
+}
+
+// collapseBlankLines drops the empty lines left by paragraphs without text
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+// extractPPTX reads a PowerPoint presentation slide by slide, in show
+// order: the slide's text, then its speaker notes and comments, each under
+// a heading naming the slide
+func extractPPTX(src source) (string, error) {
+	zr, err := zip.NewReader(src.r, src.size)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, slide := range pptxSlides(zr) {
+		data, err := zipPart(zr, slide)
+		if err != nil {
+			continue
+		}
+		rels := partRels(zr, slide)
+		section := func(title, text string) {
+			if text != "" {
+				fmt.Fprintf(&sb, "=== %s ===\n%s\n", title, text)
+			}
+		}
+		section(fmt.Sprintf("Slide %d", i+1), drawingText(data))
+		section(fmt.Sprintf("Slide %d notes", i+1), partsText(zr, relsOfType(rels, "notesSlide")))
+		section(fmt.Sprintf("Slide %d comments", i+1), partsText(zr, relsOfType(rels, "comments")))
+	}
+	return sb.String(), nil
+}
+
+// partsText joins the drawingText of several parts
+func partsText(zr *zip.Reader, parts []string) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		if data, err := zipPart(zr, part); err == nil {
+			sb.WriteString(drawingText(data))
+		}
+	}
+	return sb.String()
+}
+
+// pptxSlides lists slide parts in the order of the presentation's slide
+// list, falling back to slide number order if it cannot be read
+func pptxSlides(zr *zip.Reader) []string {
+	const main = "ppt/presentation.xml"
+	var slides []string
+	if data, err := zipPart(zr, main); err == nil {
+		var pres struct {
+			IDs []struct {
+				Rel string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+			} `xml:"sldIdLst>sldId"`
+		}
+		if xml.Unmarshal(data, &pres) == nil {
+			targets := map[string]string{}
+			for _, r := range partRels(zr, main) {
+				targets[r.ID] = r.Target
+			}
+			for _, id := range pres.IDs {
+				if t, ok := targets[id.Rel]; ok {
+					slides = append(slides, t)
+				}
+			}
+		}
+	}
+	if len(slides) > 0 {
+		return slides
+	}
+
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "ppt/slides/slide") && strings.HasSuffix(f.Name, ".xml") {
+			slides = append(slides, f.Name)
+		}
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "ppt/slides/slide"), ".xml"))
+		return n
+	}
+	sort.Slice(slides, func(i, j int) bool { return number(slides[i]) < number(slides[j]) })
+	return slides
+}
+
+// docxRelated are the parts of a Word document, besides the body, whose
+// text is read: found through the body's relationships, in this order
+var docxRelated = []string{"header", "footer", "footnotes", "endnotes", "comments"}
+
+// docxProperties are the document properties read from docProps/core.xml
+// and docProps/app.xml, by element name. Creation and modification dates
+// are left out: they describe the file, not a patient.
+var docxProperties = map[string]string{
+	"title": "Title", "subject": "Subject", "creator": "Author",
+	"lastModifiedBy": "Last Modified By", "keywords": "Keywords",
+	"description": "Comments", "category": "Category",
+	"Company": "Company", "Manager": "Manager",
+}
+
+// extractDOCX reads every part of a Word document that holds text: the
+// body (deleted tracked changes included), headers, footers, footnotes,
+// endnotes, comments and the document properties. Each part after the body
+// is headed with its name, and the Document records where each part's text
+// lies so findings can name it.
+func extractDOCX(src source) (Document, error) {
+	zr, err := zip.NewReader(src.r, src.size)
+	if err != nil {
+		return Document{}, err
+	}
+	const main = "word/document.xml"
+	body, err := zipPart(zr, main)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var doc Document
+	var sb strings.Builder
+	add := func(name, text string) {
+		if text == "" {
+			return
+		}
+		if sb.Len() > 0 {
+			fmt.Fprintf(&sb, "\n=== %s ===\n", name)
+		}
+		start := sb.Len()
+		sb.WriteString(text)
+		doc.Parts = append(doc.Parts, Part{Name: name, Start: start, End: sb.Len()})
+	}
+
+	add(main, wordprocessingText(body))
+	rels := partRels(zr, main)
+	for _, kind := range docxRelated {
+		for _, part := range relsOfType(rels, kind) {
+			if data, err := zipPart(zr, part); err == nil {
+				add(part, wordprocessingText(data))
+			}
+		}
+	}
+	for _, part := range []string{"docProps/core.xml", "docProps/app.xml", "docProps/custom.xml"} {
+		if data, err := zipPart(zr, part); err == nil {
+			add(part, propertiesText(data))
+		}
+	}
+	doc.Text = sb.String()
+	return doc, nil
+}
+
+// wordprocessingText returns the text of a WordprocessingML part, one line
+// per paragraph and table row with cells tab-separated. Deleted runs of
+// tracked changes are kept; field instructions and the fallback copies of
+// drawings in mc:AlternateContent are not.
+func wordprocessingText(data []byte) string {
+	var sb strings.Builder
+	d := xml.NewDecoder(bytes.NewReader(data))
+	inText, cells, fallback := 0, 0, 0
+	var open []string // names of the enclosing elements
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inRun := len(open) > 0 && open[len(open)-1] == "r"
+			open = append(open, t.Name.Local)
+			switch t.Name.Local {
+			case "t", "delText":
+				inText++
+			case "tc":
+				cells++
+			case "Fallback":
+				fallback++
+			}
+			if inRun && fallback == 0 {
+				switch t.Name.Local {
+				case "tab": // Outside a run, w:tab is a tab stop definition
+					sb.WriteString("\t")
+				case "br", "cr":
+					sb.WriteString("\n")
+				case "noBreakHyphen":
+					sb.WriteString("-")
+				}
+			}
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			switch t.Name.Local {
+			case "t", "delText":
+				inText--
+			case "Fallback":
+				fallback--
+			case "p":
+				if fallback > 0 {
+					break
+				}
+				if cells > 0 {
+					sb.WriteString(" ")
+				} else {
+					sb.WriteString("\n")
+				}
+			case "tc":
+				cells--
+				if fallback == 0 {
+					sb.WriteString("\t")
+				}
+			case "tr":
+				if fallback == 0 {
+					sb.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inText > 0 && fallback == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return collapseBlankLines(sb.String())
+}
+
+// propertiesText renders document properties as "Label: value" lines: the
+// docxProperties of core.xml and app.xml, and every property of custom.xml
+// under its own name
+func propertiesText(data []byte) string {
+	var sb strings.Builder
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var label string // of the property being read
+	var value strings.Builder
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 {
+				continue
+			}
+			label = docxProperties[t.Name.Local]
+			if t.Name.Local == "property" { // custom.xml
+				for _, a := range t.Attr {
+					if a.Name.Local == "name" {
+						label = a.Value
+					}
+				}
+			}
+			value.Reset()
+		case xml.EndElement:
+			if depth == 2 && label != "" {
+				if v := strings.TrimSpace(value.String()); v != "" {
+					fmt.Fprintf(&sb, "%s: %s\n", label, v)
+				}
+			}
+			depth--
+		case xml.CharData:
+			if depth >= 2 {
+				value.Write(t)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,219 @@
+package content
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// rtfSkipped are destinations whose contents are not document text: tables
+// of fonts, colours and styles, document properties, embedded pictures and
+// objects, and field instructions (the field's displayed result is kept)
+var rtfSkipped = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "listtable": true,
+	"listoverridetable": true, "revtbl": true, "rsidtbl": true, "info": true,
+	"pict": true, "object": true, "objdata": true, "fldinst": true,
+	"themedata": true, "colorschememapping": true, "datastore": true,
+	"latentstyles": true, "xmlnstbl": true, "filetbl": true, "generator": true,
+}
+
+// rtfSymbols are control words that stand for text
+var rtfSymbols = map[string]string{
+	"par": "\n", "line": "\n", "sect": "\n", "page": "\n", "row": "\n",
+	"tab": "\t", "cell": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+}
+
+func extractRTF(src source) (string, error) {
+	data, err := io.ReadAll(src.reader())
+	if err != nil {
+		return "", err
+	}
+	return stripRTF(data), nil
+}
+
+// rtfGroup is the state a "{...}" group inherits from its parent
+type rtfGroup struct {
+	skip bool // inside a destination that is not document text
+	uc   int  // characters standing in for each \uN, skipped after it
+}
+
+// stripRTF removes RTF control words and groups, keeping the document text.
+// Characters escaped as \'hh are decoded with the document's ANSI code page,
+// and \uN escapes as Unicode.
+func stripRTF(data []byte) string {
+	var (
+		sb      strings.Builder
+		group   = rtfGroup{uc: 1}
+		stack   []rtfGroup
+		ansi    encoding.Encoding = charmap.Windows1252
+		pending []byte            // \'hh bytes, decoded together for multi-byte code pages
+		high    rune              // first half of a \uN surrogate pair
+		skipN   int               // fallback characters still to skip after \uN
+		first   bool              // at the first token of a group, where destinations are named
+	)
+	flush := func() {
+		if len(pending) > 0 {
+			if text, err := ansi.NewDecoder().Bytes(pending); err == nil {
+				sb.Write(text)
+			}
+			pending = pending[:0]
+		}
+	}
+	write := func(s string) {
+		if group.skip {
+			return
+		}
+		flush()
+		sb.WriteString(s)
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, group)
+			first = true
+			continue
+		case '}':
+			if len(stack) > 0 {
+				group, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+			first = false
+			continue
+		case '\r', '\n':
+			continue // Line breaks in the source are not text
+		case '\\':
+		default:
+			switch {
+			case skipN > 0:
+				skipN--
+			case c >= 0x80:
+				if !group.skip {
+					pending = append(pending, c) // Unescaped 8-bit text
+				}
+			default:
+				write(string(c))
+			}
+			first = false
+			continue
+		}
+
+		// Control symbol or control word
+		i++
+		if i >= len(data) {
+			break
+		}
+		c = data[i]
+		wasFirst := first
+		first = false
+		switch {
+		case c == '\'':
+			if i+2 < len(data) {
+				if b, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8); err == nil {
+					if skipN > 0 {
+						skipN--
+					} else if !group.skip {
+						pending = append(pending, byte(b))
+					}
+				}
+				i += 2
+			}
+			continue
+		case c == '*':
+			group.skip = true // Ignorable destination this reader does not know
+			continue
+		case c == '~':
+			write(" ")
+			continue
+		case c == '_':
+			write("-")
+			continue
+		case c == '\\' || c == '{' || c == '}':
+			write(string(c))
+			continue
+		case c == '\r' || c == '\n':
+			write("\n")
+			continue
+		case !isLetter(c):
+			continue // \- optional hyphen and other symbols
+		}
+
+		start := i
+		for i < len(data) && isLetter(data[i]) {
+			i++
+		}
+		word := string(data[start:i])
+		numStart := i
+		if i < len(data) && data[i] == '-' {
+			i++
+		}
+		for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+			i++
+		}
+		param, hasParam := 0, i > numStart
+		if hasParam {
+			param, _ = strconv.Atoi(string(data[numStart:i]))
+		}
+		if i >= len(data) || data[i] != ' ' {
+			i-- // The delimiter belongs to the text
+		}
+
+		switch {
+		case wasFirst && rtfSkipped[word]:
+			group.skip = true
+		case word == "ansicpg" && hasParam:
+			ansi = rtfCodePage(param)
+		case word == "uc" && hasParam:
+			group.uc = param
+		case word == "u" && hasParam:
+			r := rune(param)
+			if r < 0 {
+				r += 0x10000
+			}
+			skipN = group.uc
+			switch {
+			case utf16.IsSurrogate(r) && high == 0:
+				high = r
+				continue
+			case high != 0:
+				r = utf16.DecodeRune(high, r)
+				high = 0
+			}
+			write(string(r))
+		case word == "bin" && hasParam:
+			i += param // Raw binary data
+		default:
+			if s, ok := rtfSymbols[word]; ok {
+				write(s)
+			}
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// rtfCodePage returns the decoder for an \ansicpg code page, falling back to
+// Windows-1252
+func rtfCodePage(cp int) encoding.Encoding {
+	names := map[int]string{932: "shift_jis", 936: "gbk", 949: "euc-kr", 950: "big5", 65001: "utf-8"}
+	name, ok := names[cp]
+	if !ok {
+		name = fmt.Sprintf("windows-%d", cp)
+	}
+	if enc, err := htmlindex.Get(name); err == nil {
+		return enc
+	}
+	return charmap.Windows1252
+}
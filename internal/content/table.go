@@ -0,0 +1,214 @@
+package content
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Table is one sheet of tabular data with the header row split out
+type Table struct {
+	Sheet  string     // sheet name ("" for CSV)
+	Header []string   // first non-empty row
+	Rows   [][]string // data rows, not padded to the header width
+}
+
+// IsTabular reports whether the file is a format ExtractTables understands
+func IsTabular(path string) bool {
+	format, err := DetectFormat(path)
+	return err == nil && format.Tabular()
+}
+
+// ExtractTables parses CSV/TSV/XLSX files into tables, keeping column structure
+func ExtractTables(path string) ([]Table, error) {
+	src, err := openSource(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return extractTables(src)
+}
+
+// ExtractTablesFrom parses CSV/TSV/XLSX content held in memory, such as a
+// file inside an archive
+func ExtractTablesFrom(name string, data []byte) ([]Table, error) {
+	return extractTables(memorySource(name, data))
+}
+
+func extractTables(src source) ([]Table, error) {
+	format := detect(src)
+	switch format {
+	case FormatCSV, FormatTSV:
+		records, _, err := parseDelimited(src.reader(), format)
+		if err != nil {
+			return nil, err
+		}
+		return []Table{newTable("", records)}, nil
+
+	case FormatXLSX:
+		f, err := excelize.OpenReader(src.reader())
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var tables []Table
+		for _, sheet := range f.GetSheetList() {
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				continue
+			}
+			tables = append(tables, newTable(sheet, rows))
+		}
+		return tables, nil
+
+	default:
+		return nil, fmt.Errorf("not a tabular format: %s", format)
+	}
+}
+
+// RewriteTable writes a copy of a CSV/TSV/XLSX file to outPath with every
+// data cell passed through rewrite. Header rows are left untouched, and the
+// output stays a valid file of the same format.
+func RewriteTable(path, outPath string, rewrite func(sheet string, col int, value string) string) error {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatCSV, FormatTSV:
+		records, delim, err := readDelimited(path, format)
+		if err != nil {
+			return err
+		}
+		headerSeen := false
+		for _, record := range records {
+			if !headerSeen {
+				headerSeen = !isBlankRow(record)
+				continue
+			}
+			for col, value := range record {
+				record[col] = rewrite("", col, value)
+			}
+		}
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = delim
+		if err := w.WriteAll(records); err != nil {
+			return err
+		}
+		return os.WriteFile(outPath, buf.Bytes(), 0644)
+
+	case FormatXLSX:
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		for _, sheet := range f.GetSheetList() {
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				continue
+			}
+			headerSeen := false
+			for r, row := range rows {
+				if !headerSeen {
+					headerSeen = !isBlankRow(row)
+					continue
+				}
+				for col, value := range row {
+					updated := rewrite(sheet, col, value)
+					if updated == value {
+						continue
+					}
+					cell, err := excelize.CoordinatesToCellName(col+1, r+1)
+					if err != nil {
+						return err
+					}
+					if err := f.SetCellStr(sheet, cell, updated); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return f.SaveAs(outPath)
+
+	default:
+		return fmt.Errorf("not a tabular format: %s", format)
+	}
+}
+
+// readDelimited reads a CSV/TSV file, sniffing the delimiter from the first line
+func readDelimited(path string, format Format) ([][]string, rune, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	return parseDelimited(f, format)
+}
+
+func parseDelimited(r io.Reader, format Format) ([][]string, rune, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data = []byte(decodeText(data))
+	delim := sniffDelimiter(data)
+	if format == FormatTSV {
+		delim = '\t'
+	}
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	records, err := cr.ReadAll()
+	return records, delim, err
+}
+
+// sniffDelimiter picks the most frequent of , ; | or tab on the first line
+func sniffDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', '\t', ';', '|'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// newTable splits off the first non-empty row as the header
+func newTable(sheet string, rows [][]string) Table {
+	t := Table{Sheet: sheet}
+	for i, row := range rows {
+		if isBlankRow(row) {
+			continue
+		}
+		t.Header = row
+		t.Rows = rows[i+1:]
+		break
+	}
+	return t
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+// Package liability estimates the financial exposure of PHI found on disk
+// using HIPAA civil money penalty tiers, per-record breach response costs and
+// optional state law overlays. Parameters are plain data so they can be
+// versioned and stored alongside the rest of the app configuration.
+package liability
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tier is one of the four HIPAA civil money penalty tiers (45 CFR 160.404)
+type Tier struct {
+	Level           int    `json:"level"`           // 1-4
+	Name            string `json:"name"`            // Culpability, e.g. "Reasonable Cause"
+	MinPerViolation int    `json:"minPerViolation"` // Dollars
+	MaxPerViolation int    `json:"maxPerViolation"` // Dollars
+	AnnualCap       int    `json:"annualCap"`       // Calendar-year cap for identical violations
+}
+
+// StateOverlay adds exposure under a state health privacy law on top of HIPAA
+type StateOverlay struct {
+	State     string `json:"state"`     // Two-letter code, e.g. "CA"
+	Statute   string `json:"statute"`   // e.g. "CMIA, Cal. Civ. Code 56.36"
+	PerRecord int    `json:"perRecord"` // Dollars per affected individual
+	Cap       int    `json:"cap"`       // 0 = uncapped
+	Enabled   bool   `json:"enabled"`
+}
+
+// Model is a versioned set of liability parameters
+type Model struct {
+	Name          string         `json:"name"`
+	Version       string         `json:"version"`
+	Tiers         []Tier         `json:"tiers"`
+	Tier          int            `json:"tier"`          // Tier assumed when estimating penalties
+	PenaltyBasis  string         `json:"penaltyBasis"`  // "min" or "max" per-violation amount
+	PerRecordCost int            `json:"perRecordCost"` // Breach response cost per individual
+	States        []StateOverlay `json:"states"`
+}
+
+// Estimate is the liability for a number of affected records, with the
+// model and assumptions that produced it
+type Estimate struct {
+	Model       string   `json:"model"`
+	Version     string   `json:"version"`
+	Records     int      `json:"records"`
+	Penalty     int      `json:"penalty"`    // HIPAA civil money penalty
+	BreachCost  int      `json:"breachCost"` // Notification, credit monitoring, forensics
+	StateCost   int      `json:"stateCost"`  // Sum of enabled state overlays
+	Total       int      `json:"total"`
+	Assumptions []string `json:"assumptions"` // Human readable, printed on reports
+}
+
+// Default returns the built-in model: 2024 inflation-adjusted HIPAA penalty
+// amounts (45 CFR 102.3), Tier 2 "Reasonable Cause" at the per-violation
+// minimum, and a healthcare per-record breach cost. State overlays ship
+// disabled.
+func Default() Model {
+	return Model{
+		Name:    "HIPAA CMP Tiers",
+		Version: "2024.1",
+		Tiers: []Tier{
+			{Level: 1, Name: "Lack of Knowledge", MinPerViolation: 141, MaxPerViolation: 71162, AnnualCap: 2134831},
+			{Level: 2, Name: "Reasonable Cause", MinPerViolation: 1424, MaxPerViolation: 71162, AnnualCap: 2134831},
+			{Level: 3, Name: "Willful Neglect, Corrected", MinPerViolation: 14232, MaxPerViolation: 71162, AnnualCap: 2134831},
+			{Level: 4, Name: "Willful Neglect, Not Corrected", MinPerViolation: 71162, MaxPerViolation: 2134831, AnnualCap: 2134831},
+		},
+		Tier:          2,
+		PenaltyBasis:  "min",
+		PerRecordCost: 408,
+		States: []StateOverlay{
+			{State: "CA", Statute: "CMIA, Cal. Civ. Code 56.36", PerRecord: 1000},
+			{State: "TX", Statute: "Tex. Health & Safety Code 181.201", PerRecord: 5000, Cap: 1500000},
+		},
+	}
+}
+
+// Parse decodes and validates a JSON model
+func Parse(data []byte) (Model, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Model{}, err
+	}
+	return m, m.Validate()
+}
+
+// Validate checks the model is internally consistent
+func (m Model) Validate() error {
+	if m.Version == "" {
+		return fmt.Errorf("liability model needs a version")
+	}
+	if _, ok := m.tier(m.Tier); !ok {
+		return fmt.Errorf("liability model %s has no tier %d", m.Version, m.Tier)
+	}
+	for _, t := range m.Tiers {
+		if t.MinPerViolation < 0 || t.MaxPerViolation < t.MinPerViolation || t.AnnualCap < 0 {
+			return fmt.Errorf("liability model %s: invalid amounts for tier %d", m.Version, t.Level)
+		}
+	}
+	if m.PenaltyBasis != "min" && m.PenaltyBasis != "max" {
+		return fmt.Errorf("liability model %s: penalty basis must be \"min\" or \"max\"", m.Version)
+	}
+	if m.PerRecordCost < 0 {
+		return fmt.Errorf("liability model %s: negative per-record cost", m.Version)
+	}
+	return nil
+}
+
+func (m Model) tier(level int) (Tier, bool) {
+	for _, t := range m.Tiers {
+		if t.Level == level {
+			return t, true
+		}
+	}
+	return Tier{}, false
+}
+
+// Estimate prices exposure for the given number of affected individuals.
+// Each individual counts as one violation, capped per calendar year.
+func (m Model) Estimate(records int) Estimate {
+	est := Estimate{
+		Model:   m.Name,
+		Version: m.Version,
+		Records: records,
+	}
+
+	tier, ok := m.tier(m.Tier)
+	if ok {
+		perViolation := tier.MinPerViolation
+		if m.PenaltyBasis == "max" {
+			perViolation = tier.MaxPerViolation
+		}
+		est.Penalty = capAt(records*perViolation, tier.AnnualCap)
+		est.Assumptions = append(est.Assumptions,
+			fmt.Sprintf("Tier %d (%s): $%d per violation (%s), annual cap $%d", tier.Level, tier.Name, perViolation, m.PenaltyBasis, tier.AnnualCap))
+	}
+
+	est.BreachCost = records * m.PerRecordCost
+	est.Assumptions = append(est.Assumptions, fmt.Sprintf("Breach response cost: $%d per record", m.PerRecordCost))
+
+	var states []string
+	for _, s := range m.States {
+		if !s.Enabled {
+			continue
+		}
+		est.StateCost += capAt(records*s.PerRecord, s.Cap)
+		states = append(states, fmt.Sprintf("%s $%d/record", s.State, s.PerRecord))
+	}
+	if len(states) > 0 {
+		sort.Strings(states)
+		est.Assumptions = append(est.Assumptions, "State overlays: "+strings.Join(states, ", "))
+	}
+
+	est.Assumptions = append(est.Assumptions, fmt.Sprintf("%d affected individual(s), one violation each", records))
+	est.Total = est.Penalty + est.BreachCost + est.StateCost
+	return est
+}
+
+// capAt limits amount to limit, where limit 0 means uncapped
+func capAt(amount, limit int) int {
+	if limit > 0 && amount > limit {
+		return limit
+	}
+	return amount
+}
@@ -0,0 +1,278 @@
+// Package pathfilter decides which files a scan visits: gitignore-style
+// include and exclude globs, .guardianignore files inside scanned trees, and
+// limits on depth, file size and hidden files.
+package pathfilter
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is read in every scanned directory. Its patterns use
+// .gitignore syntax and apply to that directory and everything below it.
+const IgnoreFileName = ".guardianignore"
+
+// Rules select the files a scan visits. Patterns use .gitignore syntax: a
+// pattern without a slash matches a name at any depth ("*.bak",
+// "node_modules/"), one with a slash is relative to the scan root
+// ("Archive/2019/**"), and a leading "!" re-includes what an earlier
+// pattern excluded. Later patterns win. An exclude pattern may also be a full
+// path ("/home/*/Archive"); see AnchorTo.
+type Rules struct {
+	Include     []string `json:"include"`       // If set, only files matching one of these are scanned
+	Exclude     []string `json:"exclude"`       // Applied after the built-in defaults
+	MaxDepth    int      `json:"max_depth"`     // Directory levels below the root (0 = unlimited, 1 = root only)
+	MaxFileSize int64    `json:"max_file_size"` // Bytes (0 = unlimited)
+	SkipHidden  bool     `json:"skip_hidden"`   // Leave out dot-files and dot-directories, which are scanned by default
+}
+
+// defaultExcludes skips version control, dependency trees, sync and trash
+// folders, Office lock files and Guardian's own redacted output
+var defaultExcludes = []string{
+	".git/", ".svn/", ".hg/",
+	"node_modules/", "__pycache__/", ".venv/",
+	".dropbox.cache/", ".tmp.drivedownload/", ".tmp.driveupload/",
+	".Trash/", ".Trashes/", "$RECYCLE.BIN/", "System Volume Information/",
+	"~$*",
+	"*_CLEANED.*", "*_CLEANED_TRANSCRIPT.txt", "*_certificate.pdf",
+}
+
+// DefaultExcludes returns the patterns every scan starts from. A rule such as
+// "!node_modules/" re-includes one of them.
+func DefaultExcludes() []string {
+	return append([]string(nil), defaultExcludes...)
+}
+
+// Merge layers more specific rules (a scan path's or a job's) over r: their
+// patterns come later so they take precedence, and their non-zero limits
+// replace r's
+func (r Rules) Merge(other Rules) Rules {
+	merged := Rules{
+		Include:     append(append([]string(nil), r.Include...), other.Include...),
+		Exclude:     append(append([]string(nil), r.Exclude...), other.Exclude...),
+		MaxDepth:    r.MaxDepth,
+		MaxFileSize: r.MaxFileSize,
+		SkipHidden:  r.SkipHidden || other.SkipHidden,
+	}
+	if other.MaxDepth > 0 {
+		merged.MaxDepth = other.MaxDepth
+	}
+	if other.MaxFileSize > 0 {
+		merged.MaxFileSize = other.MaxFileSize
+	}
+	return merged
+}
+
+// Filter applies Rules beneath one scan root. It caches ignore files as it
+// goes and is not safe for concurrent use.
+type Filter struct {
+	root    string
+	rules   Rules
+	exclude []pattern
+	include []pattern
+	ignores map[string][]pattern // .guardianignore patterns by directory (slash path relative to root)
+}
+
+// New compiles rules for a scan root
+func New(root string, rules Rules) *Filter {
+	f := &Filter{root: root, rules: rules, ignores: map[string][]pattern{}}
+	for _, line := range append(DefaultExcludes(), rules.Exclude...) {
+		line, ok := AnchorTo(line, root)
+		if !ok {
+			continue // A full path elsewhere on disk
+		}
+		if p, ok := compile(line, ""); ok {
+			f.exclude = append(f.exclude, p)
+		}
+	}
+	for _, line := range rules.Include {
+		if p, ok := compile(line, ""); ok {
+			f.include = append(f.include, p)
+		}
+	}
+	return f
+}
+
+// Walk calls fn for every file under the root that the rules admit, in
+// lexical order. Excluded, hidden and too-deep directories are not entered,
+// unreadable entries are skipped, and an error returned by fn stops the walk.
+func (f *Filter) Walk(fn func(path string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(f.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors accessing files
+		}
+		rel, relErr := filepath.Rel(f.root, path)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == "." {
+			if d.IsDir() {
+				f.loadIgnoreFile(path, "")
+				return nil
+			}
+			return fn(path, d) // The root is a single file
+		}
+
+		if d.IsDir() {
+			if !f.allowDir(rel, d.Name()) {
+				return filepath.SkipDir
+			}
+			f.loadIgnoreFile(path, rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || !f.allowFile(rel, d) {
+			return nil
+		}
+		return fn(path, d)
+	})
+}
+
+// Allows reports whether the file at path, beneath the root, would be
+// visited by Walk. Ignore files are read along the way.
+func (f *Filter) Allows(path string) bool {
+	rel, ok := f.rel(path)
+	if !ok {
+		return false
+	}
+	if rel == "." {
+		return true
+	}
+	parts := strings.Split(rel, "/")
+	if !f.enters(parts[:len(parts)-1]) {
+		return false
+	}
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return f.allowFile(rel, fs.FileInfoToDirEntry(info))
+}
+
+// AllowsDir reports whether Walk would enter the directory at path, which
+// is the root or beneath it. Ignore files are read along the way.
+func (f *Filter) AllowsDir(path string) bool {
+	rel, ok := f.rel(path)
+	if !ok {
+		return false
+	}
+	return rel == "." || f.enters(strings.Split(rel, "/"))
+}
+
+// rel returns path as a slash path relative to the root
+func (f *Filter) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// enters reports whether Walk would descend through the directories dirs,
+// given as the segments of a path relative to the root
+func (f *Filter) enters(dirs []string) bool {
+	f.loadIgnoreFile(f.root, "")
+	for i := range dirs {
+		dir := strings.Join(dirs[:i+1], "/")
+		if !f.allowDir(dir, dirs[i]) {
+			return false
+		}
+		f.loadIgnoreFile(filepath.Join(f.root, filepath.FromSlash(dir)), dir)
+	}
+	return true
+}
+
+func (f *Filter) allowDir(rel, name string) bool {
+	if f.rules.SkipHidden && isHidden(name) {
+		return false
+	}
+	// Files in a directory at depth MaxDepth would be one level too deep
+	if f.rules.MaxDepth > 0 && depth(rel) >= f.rules.MaxDepth {
+		return false
+	}
+	return !f.excluded(rel, true)
+}
+
+func (f *Filter) allowFile(rel string, d fs.DirEntry) bool {
+	if f.rules.SkipHidden && isHidden(d.Name()) {
+		return false
+	}
+	if f.rules.MaxDepth > 0 && depth(rel) > f.rules.MaxDepth {
+		return false
+	}
+	if f.excluded(rel, false) {
+		return false
+	}
+	if len(f.include) > 0 && !lastMatch(f.include, rel, false) {
+		return false
+	}
+	if f.rules.MaxFileSize > 0 {
+		if info, err := d.Info(); err == nil && info.Size() > f.rules.MaxFileSize {
+			return false
+		}
+	}
+	return true
+}
+
+// excluded applies the rule patterns, then ignore files from the root down,
+// so a deeper .guardianignore can override a shallower one
+func (f *Filter) excluded(rel string, isDir bool) bool {
+	ex := false
+	for _, p := range f.exclude {
+		if p.match(rel, isDir) {
+			ex = !p.negate
+		}
+	}
+	parts := strings.Split(rel, "/")
+	for i := 0; i < len(parts); i++ {
+		for _, p := range f.ignores[strings.Join(parts[:i], "/")] {
+			if p.match(rel, isDir) {
+				ex = !p.negate
+			}
+		}
+	}
+	return ex
+}
+
+// lastMatch reports whether the last pattern matching rel is a positive one
+func lastMatch(patterns []pattern, rel string, isDir bool) bool {
+	matched := false
+	for _, p := range patterns {
+		if p.match(rel, isDir) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// loadIgnoreFile reads dir's .guardianignore, once
+func (f *Filter) loadIgnoreFile(dir, rel string) {
+	if _, seen := f.ignores[rel]; seen {
+		return
+	}
+	f.ignores[rel] = nil
+	file, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if p, ok := compile(scanner.Text(), rel); ok {
+			f.ignores[rel] = append(f.ignores[rel], p)
+		}
+	}
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+func depth(rel string) int {
+	return strings.Count(rel, "/") + 1
+}
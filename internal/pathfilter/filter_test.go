@@ -0,0 +1,143 @@
+package pathfilter
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tree creates files (slash paths relative to the returned root) with the
+// given contents
+func tree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// walked lists the files Walk visits, as sorted slash paths relative to root
+func walked(t *testing.T, root string, rules Rules) []string {
+	t.Helper()
+	var got []string
+	err := New(root, rules).Walk(func(path string, d fs.DirEntry) error {
+		rel, _ := filepath.Rel(root, path)
+		got = append(got, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestWalk(t *testing.T) {
+	files := map[string]string{
+		"a.txt":                     "a",
+		"big.txt":                   strings.Repeat("x", 100),
+		"notes.bak":                 "b",
+		".hidden/secret.txt":        "s",
+		".env":                      "e",
+		"node_modules/pkg/x.js":     "x",
+		"Archive/2019/old.txt":      "o",
+		"Archive/2020/keep.txt":     "k",
+		"deep/one/two/three.txt":    "3",
+		"report_CLEANED.txt":        "c",
+		"sub/.guardianignore":       "*.log\n!keep.log\n",
+		"sub/app.log":               "l",
+		"sub/keep.log":              "k",
+		"sub/inner/.guardianignore": "!app.log\n",
+		"sub/inner/app.log":         "l",
+	}
+	root := tree(t, files)
+
+	tests := []struct {
+		name  string
+		rules Rules
+		want  []string
+	}{
+		{"defaults", Rules{}, []string{
+			".env", ".hidden/secret.txt", "Archive/2019/old.txt", "Archive/2020/keep.txt",
+			"a.txt", "big.txt", "deep/one/two/three.txt", "notes.bak",
+			"sub/.guardianignore", "sub/inner/.guardianignore", "sub/inner/app.log", "sub/keep.log",
+		}},
+		{"exclude and negate", Rules{Exclude: []string{"*.bak", "Archive/*/*.txt", "!Archive/2020/keep.txt", "!node_modules/"}}, []string{
+			".env", ".hidden/secret.txt", "Archive/2020/keep.txt",
+			"a.txt", "big.txt", "deep/one/two/three.txt", "node_modules/pkg/x.js",
+			"sub/.guardianignore", "sub/inner/.guardianignore", "sub/inner/app.log", "sub/keep.log",
+		}},
+		{"include", Rules{Include: []string{"*.txt"}, Exclude: []string{"sub/"}}, []string{
+			".hidden/secret.txt", "Archive/2019/old.txt", "Archive/2020/keep.txt",
+			"a.txt", "big.txt", "deep/one/two/three.txt",
+		}},
+		{"depth", Rules{MaxDepth: 2, Include: []string{"*.txt"}}, []string{
+			".hidden/secret.txt", "a.txt", "big.txt",
+		}},
+		{"size", Rules{MaxFileSize: 10, Include: []string{"*.txt"}}, []string{
+			".hidden/secret.txt", "Archive/2019/old.txt", "Archive/2020/keep.txt",
+			"a.txt", "deep/one/two/three.txt",
+		}},
+		{"skip hidden", Rules{SkipHidden: true, Include: []string{"*.txt", ".env"}}, []string{
+			"Archive/2019/old.txt", "Archive/2020/keep.txt", "a.txt", "big.txt", "deep/one/two/three.txt",
+		}},
+		{"root-anchored exclude", Rules{Exclude: []string{"/deep/", "/sub"}}, []string{
+			".env", ".hidden/secret.txt", "Archive/2019/old.txt", "Archive/2020/keep.txt",
+			"a.txt", "big.txt", "notes.bak",
+		}},
+		{"full-path exclude", Rules{Exclude: []string{filepath.ToSlash(root) + "/Archive/", "/elsewhere/*/deep"}}, []string{
+			".env", ".hidden/secret.txt", "a.txt", "big.txt", "deep/one/two/three.txt", "notes.bak",
+			"sub/.guardianignore", "sub/inner/.guardianignore", "sub/inner/app.log", "sub/keep.log",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := walked(t, root, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walked %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsMatchesWalk(t *testing.T) {
+	root := tree(t, map[string]string{
+		"a.txt":               "a",
+		"node_modules/x.js":   "x",
+		"sub/.guardianignore": "*.log\n",
+		"sub/app.log":         "l",
+		"sub/b.txt":           "b",
+	})
+	f := New(root, Rules{})
+	for rel, want := range map[string]bool{
+		"a.txt":             true,
+		"node_modules/x.js": false,
+		"sub/app.log":       false,
+		"sub/b.txt":         true,
+		"missing.txt":       false,
+	} {
+		if got := f.Allows(filepath.Join(root, filepath.FromSlash(rel))); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if f.Allows(filepath.Join(filepath.Dir(root), "outside.txt")) {
+		t.Error("Allows admits a path outside the root")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Rules{Exclude: []string{"*.bak"}, MaxDepth: 3, MaxFileSize: 100}
+	got := base.Merge(Rules{Exclude: []string{"!keep.bak"}, MaxFileSize: 50, SkipHidden: true})
+	want := Rules{Exclude: []string{"*.bak", "!keep.bak"}, MaxDepth: 3, MaxFileSize: 50, SkipHidden: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package pathfilter
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pattern is one compiled gitignore-style line
+type pattern struct {
+	source   string   // the line as written, for debugging
+	base     string   // slash path, relative to the scan root, the pattern is relative to
+	negate   bool     // "!pattern" re-includes
+	dirOnly  bool     // "pattern/" only matches directories
+	anchored bool     // contains a slash: matched against the whole relative path
+	segments []string // split on "/"; "**" matches any number of segments
+}
+
+// compile parses a gitignore line. It returns false for blank lines and comments.
+func compile(line, base string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+	p := pattern{source: line, base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	line = strings.ReplaceAll(line, `\`, "/") // Windows-style separators in user rules
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+// AnchorTo rewrites an exclude pattern written as a full path so it applies
+// beneath root: "/home/*/Archive" becomes "/Archive" under /home/alice. A
+// pattern counts as a full path when it starts with a drive
+// ("C:/Users/*/Archive"), which does not apply (ok is false) under another
+// folder, or when its leading segments match the root's own path. Any other
+// leading slash anchors the pattern to the root as in .gitignore, so "/tmp/"
+// under /home/alice means /home/alice/tmp. Patterns that are not full paths
+// are returned unchanged.
+func AnchorTo(line, root string) (string, bool) {
+	negate := ""
+	body := line
+	if strings.HasPrefix(body, "!") {
+		negate, body = "!", body[1:]
+	}
+	body = strings.ReplaceAll(body, `\`, "/")
+	dirOnly := strings.HasSuffix(body, "/")
+	globs := splitPath(body)
+	rootParts := splitPath(strings.ReplaceAll(filepath.Clean(root), `\`, "/"))
+	if len(globs) == 0 || len(rootParts) == 0 || !hasDrive(body) && !strings.HasPrefix(body, "/") {
+		return line, true
+	}
+
+	rest, ok := beneath(globs, rootParts)
+	if !ok {
+		if hasDrive(body) {
+			return "", false // A full path elsewhere on disk
+		}
+		return line, true
+	}
+	anchored := negate + "/" + strings.Join(rest, "/")
+	if dirOnly && rest[len(rest)-1] != "**" {
+		anchored += "/"
+	}
+	return anchored, true
+}
+
+// beneath matches the leading glob segments against the root's path and
+// returns the segments left to match below it. It returns false when the
+// globs lead somewhere other than the root.
+func beneath(globs, rootParts []string) ([]string, bool) {
+	for i, part := range rootParts {
+		if i == len(globs) {
+			return []string{"**"}, true // Stops above the root: matches all of it
+		}
+		if globs[i] == "**" {
+			return globs[i:], true
+		}
+		if ok, _ := path.Match(globs[i], part); !ok {
+			return nil, false
+		}
+	}
+	if len(globs) == len(rootParts) {
+		return []string{"**"}, true // Names the root itself
+	}
+	return globs[len(rootParts):], true
+}
+
+// hasDrive reports whether a slash path starts with a Windows drive letter
+func hasDrive(p string) bool {
+	return len(p) >= 3 && p[1] == ':' && p[2] == '/' &&
+		(p[0] >= 'A' && p[0] <= 'Z' || p[0] >= 'a' && p[0] <= 'z')
+}
+
+// splitPath splits a slash path into its non-empty segments
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool { return r == '/' })
+}
+
+// match reports whether the pattern applies to rel, a slash path relative to
+// the scan root
+func (p pattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, p.base+"/")
+	}
+	parts := strings.Split(rel, "/")
+	if !p.anchored {
+		// No slash: match the name at any depth
+		ok, _ := path.Match(p.segments[0], parts[len(parts)-1])
+		return ok
+	}
+	return matchSegments(p.segments, parts)
+}
+
+// matchSegments matches glob segments against path segments, letting "**"
+// stand for zero or more whole segments
+func matchSegments(globs, parts []string) bool {
+	for len(globs) > 0 {
+		if globs[0] == "**" {
+			rest := globs[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(globs[0], parts[0]); !ok {
+			return false
+		}
+		globs, parts = globs[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
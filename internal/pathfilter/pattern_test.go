@@ -0,0 +1,82 @@
+package pathfilter
+
+import "testing"
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		line  string
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"*.bak", "notes.bak", false, true},
+		{"*.bak", "a/b/notes.bak", false, true},
+		{"*.bak", "notes.txt", false, false},
+		{"node_modules/", "web/node_modules", true, true},
+		{"node_modules/", "web/node_modules", false, false},
+		{"Archive/2019/**", "Archive/2019/q1/report.pdf", false, true},
+		{"Archive/2019/**", "Old/Archive/2019/report.pdf", false, false},
+		{"/tmp/", "tmp", true, true},
+		{"/tmp/", "a/tmp", true, false},
+		{"**/drafts", "a/b/drafts", true, true},
+		{"**/drafts", "drafts", true, true},
+		{`Archive\2019`, "Archive/2019", true, true},
+		{"~$*", "~$report.docx", false, true},
+	}
+	for _, tt := range tests {
+		p, ok := compile(tt.line, "")
+		if !ok {
+			t.Fatalf("compile(%q) failed", tt.line)
+		}
+		if got := p.match(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("%q matching %q (dir %v) = %v, want %v", tt.line, tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestCompileSkipsBlankAndComments(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok := compile(line, ""); ok {
+			t.Errorf("compile(%q) = ok, want skipped", line)
+		}
+	}
+	if p, ok := compile(`\#literal`, ""); !ok || p.negate || !p.match("#literal", false) {
+		t.Errorf(`compile("\#literal") does not match "#literal"`)
+	}
+}
+
+func TestAnchorTo(t *testing.T) {
+	tests := []struct {
+		line   string
+		root   string
+		want   string
+		wantOK bool
+	}{
+		// Full paths through the root are rewritten beneath it
+		{"/home/*/Archive", "/home/alice", "/Archive", true},
+		{"/home/*/Archive/", "/home/alice", "/Archive/", true},
+		{"!/home/*/Archive", "/home/alice", "!/Archive", true},
+		{"/home/alice", "/home/alice", "/**", true},
+		{"/home", "/home/alice", "/**", true},
+		{"/home/**/cache", "/home/alice", "/**/cache", true},
+		{"C:/Users/*/AppData", "C:/Users/bob", "/AppData", true},
+		{`C:\Users\*\AppData`, "C:/Users/bob", "/AppData", true},
+		// A drive path elsewhere does not apply
+		{"D:/Backups", "C:/Users/bob", "", false},
+		// Other leading slashes keep their .gitignore meaning, whatever
+		// exists at the top of this machine's filesystem
+		{"/tmp/", "/root/module", "/tmp/", true},
+		{"/home", "/root/module", "/home", true},
+		{"/usr/**", "/root/module", "/usr/**", true},
+		{"/home/*/Archive", "/srv/share", "/home/*/Archive", true},
+		// Relative patterns are untouched
+		{"*.bak", "/home/alice", "*.bak", true},
+		{"Archive/2019/**", "/home/alice", "Archive/2019/**", true},
+	}
+	for _, tt := range tests {
+		got, ok := AnchorTo(tt.line, tt.root)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("AnchorTo(%q, %q) = %q, %v; want %q, %v", tt.line, tt.root, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
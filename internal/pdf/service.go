@@ -3,6 +3,7 @@ package pdf
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -125,6 +126,18 @@ type OffenderData interface {
 	GetEstimatedFine() int
 }
 
+// AuditSummary is the headline data printed at the top of an audit report
+type AuditSummary struct {
+	TotalFiles      int
+	CriticalCount   int
+	Liability       int
+	Individuals     int
+	LiabilityModel  string         // e.g. "HIPAA CMP Tiers v2024.1"
+	Assumptions     []string       // How Liability was derived
+	Unscanned       [][]string     // File, format and reason for each file that could not be read
+	SkippedBinaries map[string]int // Executables, media and other unreadable binaries by extension
+}
+
 // GenerateAuditReport creates a detailed PDF report
 // We accept interface{} and manually cast or use struct from risk package to avoid circular deps
 // ideally we should move shared types to a separate package. For now, we redefine a local struct for input
@@ -133,7 +146,10 @@ type OffenderData interface {
 // let's just duplicate the struct definition locally or accept distinct params.
 // ACTUALLY: app.go imports both, so they are siblings. risk imports nothing from pdf.
 // So we can import "hipaa-app/internal/risk" here if we wanted, but// GenerateAuditReport creates a detailed PDF report
-func (s *PDFService) GenerateAuditReport(totalFiles, criticalCount, liability int, topOffenders [][]string, outputPath string) (string, error) {
+// findings rows are {file, line:column, detector, category, confidence} and may be empty
+func (s *PDFService) GenerateAuditReport(summary AuditSummary, topOffenders [][]string, findings [][]string, outputPath string) (string, error) {
+	totalFiles, criticalCount, liability, individuals := summary.TotalFiles, summary.CriticalCount, summary.Liability, summary.Individuals
+
 	m := pdf.NewMaroto(consts.Portrait, consts.A4)
 	m.SetPageMargins(20, 10, 20)
 
@@ -171,6 +187,37 @@ func (s *PDFService) GenerateAuditReport(totalFiles, criticalCount, liability in
 		})
 	})
 
+	// Liability basis
+	if summary.LiabilityModel != "" {
+		m.Row(8, func() {
+			m.Col(12, func() {
+				m.Text("Liability model: "+summary.LiabilityModel, props.Text{Size: 9, Style: consts.Bold})
+			})
+		})
+		for _, assumption := range summary.Assumptions {
+			text := "- " + assumption
+			m.Row(5, func() {
+				m.Col(12, func() {
+					m.Text(text, props.Text{Size: 8, Left: 4})
+				})
+			})
+		}
+	}
+
+	// Breach notification exposure
+	individualsNote := "Below the HHS 500-individual breach notification threshold"
+	individualsColor := color.Color{Red: 0, Green: 0, Blue: 0}
+	if individuals >= 500 {
+		individualsNote = "HHS and media notification required if breached (500+ individuals)"
+		individualsColor = color.Color{Red: 255, Green: 0, Blue: 0}
+	}
+	m.Row(15, func() {
+		m.Col(12, func() {
+			m.Text(fmt.Sprintf("Estimated Individuals Affected: %d", individuals), props.Text{Size: 12, Style: consts.Bold})
+			m.Text(individualsNote, props.Text{Size: 9, Top: 6, Color: individualsColor})
+		})
+	})
+
 	// Top Offenders Table
 	m.Row(10, func() {
 		m.Col(12, func() {
@@ -190,6 +237,67 @@ func (s *PDFService) GenerateAuditReport(totalFiles, criticalCount, liability in
 		},
 	})
 
+	// Finding Details Table
+	if len(findings) > 0 {
+		m.Row(10, func() {
+			m.Col(12, func() {
+				m.Text("Identifier Findings", props.Text{Size: 14, Style: consts.Bold, Top: 5})
+			})
+		})
+
+		findingHeader := []string{"File", "Line:Col", "Detector", "Category", "Confidence"}
+		m.TableList(findingHeader, findings, props.TableList{
+			HeaderProp: props.TableListContent{
+				Size:      10,
+				GridSizes: []uint{4, 2, 2, 3, 1},
+			},
+			ContentProp: props.TableListContent{
+				Size:      8,
+				GridSizes: []uint{4, 2, 2, 3, 1},
+			},
+		})
+	}
+
+	// Files the scan could not read
+	if len(summary.Unscanned) > 0 {
+		m.Row(10, func() {
+			m.Col(12, func() {
+				m.Text(fmt.Sprintf("Files Not Scanned: %d (review manually)", len(summary.Unscanned)), props.Text{Size: 14, Style: consts.Bold, Top: 5})
+			})
+		})
+
+		m.TableList([]string{"File", "Format", "Reason"}, summary.Unscanned, props.TableList{
+			HeaderProp: props.TableListContent{
+				Size:      10,
+				GridSizes: []uint{5, 2, 5},
+			},
+			ContentProp: props.TableListContent{
+				Size:      8,
+				GridSizes: []uint{5, 2, 5},
+			},
+		})
+	}
+
+	// Binaries have no text to scan; they are counted rather than listed
+	if len(summary.SkippedBinaries) > 0 {
+		exts := make([]string, 0, len(summary.SkippedBinaries))
+		total := 0
+		for ext, n := range summary.SkippedBinaries {
+			exts = append(exts, ext)
+			total += n
+		}
+		sort.Strings(exts)
+		counts := make([]string, len(exts))
+		for i, ext := range exts {
+			counts[i] = fmt.Sprintf("%s %d", ext, summary.SkippedBinaries[ext])
+		}
+		m.Row(8, func() {
+			m.Col(12, func() {
+				m.Text(fmt.Sprintf("Binary files skipped (no readable format): %d (%s)", total, strings.Join(counts, ", ")), props.Text{Size: 9, Top: 3})
+			})
+		})
+	}
+
 	// Output
 	finalPath := outputPath
 	if finalPath == "" {
@@ -0,0 +1,112 @@
+package risk
+
+import (
+	_ "embed"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// US states, DC and territories ("AB Full Name" per line)
+//
+//go:embed data/us_states.txt
+var usStatesData string
+
+var addressZipTail = regexp.MustCompile(`\d{5}(?:-\d{4})?$`)
+
+// addressQualifier is the word right before a bare "address" cue
+var addressQualifier = regexp.MustCompile(`([A-Za-z][A-Za-z\-]*)[ \t]*$`)
+
+// notPostal are qualifiers that make "address:" a network or mail address
+var notPostal = map[string]bool{"ip": true, "ipv4": true, "ipv6": true, "mac": true, "email": true, "e-mail": true, "web": true}
+
+// AddressDetector finds street addresses and sub-state geography
+// (HIPAA identifier #2): house number + street suffix with optional unit,
+// PO boxes, "City, ST 12345" sequences, counties and "City:" style fields.
+// Redaction keeps only the state, which Safe Harbor permits.
+type AddressDetector struct {
+	street       *regexp.Regexp
+	poBox        *regexp.Regexp
+	cityStateZip *regexp.Regexp
+	county       *regexp.Regexp
+	cue          *regexp.Regexp
+	stateTail    *regexp.Regexp
+}
+
+// NewAddressDetector compiles the address patterns against the embedded state list
+func NewAddressDetector() Detector {
+	var states []string
+	for _, line := range strings.Split(usStatesData, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		abbr, name, _ := strings.Cut(line, " ")
+		states = append(states, abbr, strings.ReplaceAll(regexp.QuoteMeta(name), " ", `[ \t]+`))
+	}
+	// Longest first so "West Virginia" wins over "Virginia"
+	sort.SliceStable(states, func(i, j int) bool { return len(states[i]) > len(states[j]) })
+	state := `(?:` + strings.Join(states, "|") + `)`
+
+	city := `[A-Z][a-z]+(?:[ \t]+[A-Z][a-z]+){0,2}`
+	zip := `\d{5}(?:-\d{4})?`
+	suffix := `(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Drive|Dr|Lane|Ln|Court|Ct|Way|Place|Pl|Terrace|Ter|Circle|Cir|Parkway|Pkwy|Highway|Hwy|Trail|Trl|Square|Sq|Loop|Pike|Alley|Aly)\b\.?`
+	unit := `(?:,?[ \t]*(?:Apt|Apartment|Unit|Suite|Ste|Rm|Room|Bldg|Building|Fl|Floor|#)\.?[ \t]*#?[A-Za-z0-9\-]+)?`
+	tail := `(?:,?[ \t]+` + city + `,?[ \t]+` + state + `\b(?:[ \t]+` + zip + `)?)?`
+
+	return &AddressDetector{
+		street: regexp.MustCompile(`\b\d{1,6}[A-Za-z]?[ \t]+(?:(?:[NSEW]\.?|North|South|East|West)[ \t]+)?(?:[A-Z0-9][A-Za-z0-9'\-]*[ \t]+){1,4}` +
+			suffix + `(?:[ \t]+(?:NE|NW|SE|SW|[NSEW])\b\.?)?` + unit + tail),
+		poBox:        regexp.MustCompile(`(?i:\b(?:P\.?[ \t]?O\.?|Post[ \t]+Office)[ \t]*Box[ \t]+\d+)` + tail),
+		cityStateZip: regexp.MustCompile(`\b` + city + `,[ \t]*` + state + `\b(?:[ \t]+` + zip + `\b)?`),
+		county:       regexp.MustCompile(`\b[A-Z][a-z]+(?:[ \t]+[A-Z][a-z]+)?[ \t]+(?:County|Parish|Borough)\b`),
+		cue:          regexp.MustCompile(`(?i:\b(city|town|county|home[ \t]+address|street[ \t]+address|address)[ \t]*:[ \t]*)([^\n\r;|]*[^\s;|])`),
+		stateTail:    regexp.MustCompile(`(?:,|[ \t])[ \t]*(` + state + `)(?:[ \t]+` + zip + `)?[ \t]*$`),
+	}
+}
+
+func (d *AddressDetector) Name() string    { return "Address" }
+func (d *AddressDetector) Identifier() int { return 2 }
+func (d *AddressDetector) Weight() int     { return 8 }
+
+func (d *AddressDetector) Find(text string) []Match {
+	var matches []Match
+	add := func(re *regexp.Regexp, confidence float64) {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			matches = append(matches, Match{Start: loc[0], End: loc[1], Value: text[loc[0]:loc[1]], Confidence: confidence})
+		}
+	}
+	add(d.street, 0.9)
+	add(d.poBox, 0.95)
+	add(d.county, 0.7)
+
+	for _, loc := range d.cityStateZip.FindAllStringIndex(text, -1) {
+		confidence := 0.7
+		if addressZipTail.MatchString(text[loc[0]:loc[1]]) {
+			confidence = 0.95
+		}
+		matches = append(matches, Match{Start: loc[0], End: loc[1], Value: text[loc[0]:loc[1]], Confidence: confidence})
+	}
+	for _, loc := range d.cue.FindAllStringSubmatchIndex(text, -1) {
+		// "IP address:", "Email address:" and the like are not postal
+		if strings.EqualFold(text[loc[2]:loc[3]], "address") {
+			before := text[max(0, loc[2]-16):loc[2]]
+			if q := addressQualifier.FindStringSubmatch(before); q != nil && notPostal[strings.ToLower(q[1])] {
+				continue
+			}
+		}
+		matches = append(matches, Match{Start: loc[4], End: loc[5], Value: text[loc[4]:loc[5]], Confidence: 0.85})
+	}
+
+	return mergeOverlapping(text, matches)
+}
+
+// Redact replaces each address with [REDACTED-ADDRESS], keeping a trailing state
+func (d *AddressDetector) Redact(text string) string {
+	return replaceMatches(text, d.Find(text), func(m Match) string {
+		if sm := d.stateTail.FindStringSubmatch(m.Value); sm != nil {
+			return "[REDACTED-ADDRESS], " + sm[1]
+		}
+		return "[REDACTED-ADDRESS]"
+	})
+}
@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"strings"
+	"testing"
+
+	"hipaa-app/internal/content"
+)
+
+func TestAddressCueSkipsNetworkAndMailAddresses(t *testing.T) {
+	d := NewAddressDetector()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Server IP address: 10.20.30.40", nil},
+		{"Email address: jane@example.com", nil},
+		{"E-mail address: jane@example.com", nil},
+		{"MAC address: 00:1A:2B:3C:4D:5E", nil},
+		{"Web address: www.example.com", nil},
+		{"Address: 12 Elm Street, Springfield, IL 62701", []string{"12 Elm Street, Springfield, IL 62701"}},
+		{"Home address: Rural Route 4", []string{"Rural Route 4"}},
+		{"Mailing address: Rural Route 4", []string{"Rural Route 4"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range d.Find(tt.text) {
+			got = append(got, m.Value)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeReportsIPAddressAsIP(t *testing.T) {
+	e := NewRiskEngine()
+	profile := e.analyzeText("log.txt", content.Document{Text: "Server IP address: 10.20.30.40"}, nil)
+	if len(profile.Details) != 1 || profile.Details[0].Detector != "IP Address" {
+		t.Fatalf("findings = %+v, want one IP Address", profile.Details)
+	}
+}
+
+func TestRedactKeepsEmailLabel(t *testing.T) {
+	got := string(NewRiskEngine().RedactContent([]byte("Email address: jane@example.com")))
+	if strings.Contains(got, "[REDACTED-ADDRESS]") || !strings.Contains(got, "[REDACTED-EMAIL]") {
+		t.Errorf("RedactContent = %q, want the email redacted as an email", got)
+	}
+}
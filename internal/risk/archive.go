@@ -0,0 +1,99 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+
+	"hipaa-app/internal/content"
+)
+
+// ArchiveResult is what AnalyzeArchive found inside one container
+type ArchiveResult struct {
+	Members   []RiskProfile   // One per readable file; FilePath is its virtual path ("export.zip!/2024/patients.csv")
+	Unscanned []UnscannedFile // Encrypted, over a limit, corrupt or unsupported
+	// Members of no readable format (executables, media, fonts) by extension
+	SkippedBinaries map[string]int
+}
+
+// AnalyzeArchive scans every file inside a ZIP, TAR or GZIP archive or a
+// mail file, nested archives and attachments included, without writing anything to disk. Contents that cannot
+// be read are listed in Unscanned rather than passed as clean; the error is
+// only returned when the archive cannot be opened at all.
+func (e *RiskEngine) AnalyzeArchive(path string, limits content.ArchiveLimits) (ArchiveResult, error) {
+	var result ArchiveResult
+	err := content.WalkArchive(path, limits, func(m content.Member) error {
+		if m.Err != nil {
+			result.Unscanned = append(result.Unscanned, UnscannedFile{Path: m.Path, Format: string(m.Format), Reason: m.Err.Error()})
+			return nil
+		}
+		if m.Format == content.FormatUnknown {
+			result.SkippedBinaries = countBinary(result.SkippedBinaries, m.Path)
+			return nil
+		}
+		doc, err := content.ExtractMember(m)
+		if err != nil {
+			result.Unscanned = append(result.Unscanned, UnscannedFile{Path: m.Path, Format: string(m.Format), Reason: err.Error()})
+			return nil
+		}
+		var tables []content.Table
+		if m.Format.Tabular() {
+			tables, _ = content.ExtractTablesFrom(m.Path, m.Data)
+		}
+		result.Members = append(result.Members, e.analyzeText(m.Path, doc, tables))
+		return nil
+	})
+	if err != nil && len(result.Members) == 0 && len(result.Unscanned) == 0 && len(result.SkippedBinaries) == 0 {
+		return result, err
+	}
+	if err != nil {
+		// Stopped part way (e.g. the total size limit): what was read stands,
+		// the rest of the archive needs a manual review
+		format, _ := content.DetectFormat(path)
+		result.Unscanned = append(result.Unscanned, unscanned(path, format, err))
+	}
+	sortUnscanned(result.Unscanned)
+	return result, nil
+}
+
+// identifiesPeople reports whether any file inside counted individuals
+func (r ArchiveResult) identifiesPeople() bool {
+	for _, m := range r.Members {
+		if m.UniqueIndividuals > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutValueHashes copies the result for persisting, see withoutValueHashes
+func (r ArchiveResult) withoutValueHashes() ArchiveResult {
+	stored := r
+	stored.Members = make([]RiskProfile, len(r.Members))
+	for i, m := range r.Members {
+		stored.Members[i] = withoutValueHashes(m)
+	}
+	return stored
+}
+
+// analyzeArchiveFile sums an archive's files into one RiskReport. Findings
+// name the file inside the archive they came from, and an archive with
+// unreadable contents is never reported clean.
+func (e *RiskEngine) analyzeArchiveFile(path string) (RiskReport, error) {
+	result, err := e.AnalyzeArchive(path, e.ScanOptions().Archives)
+	if err != nil {
+		return RiskReport{}, err
+	}
+	report := RiskReport{Findings: []string{}}
+	for _, profile := range result.Members {
+		report.RiskScore += profile.RiskScore
+		name := strings.TrimPrefix(profile.FilePath, path+content.ArchiveSeparator)
+		for _, finding := range profile.Findings {
+			report.Findings = append(report.Findings, fmt.Sprintf("%s: %s", name, finding))
+		}
+	}
+	for _, u := range result.Unscanned {
+		report.Findings = append(report.Findings, fmt.Sprintf("Not scanned: %s (%s)", u.Path, u.Reason))
+	}
+	report.IsClean = report.RiskScore == 0 && len(result.Unscanned) == 0
+	return report, nil
+}
@@ -0,0 +1,188 @@
+package risk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"hipaa-app/internal/content"
+)
+
+// EngineVersion is bumped whenever detection or scoring code changes in a way
+// that makes previously cached results stale. Extraction changes bump
+// content.Version, and detector patterns are hashed via Definer.
+const EngineVersion = 3
+
+// CachedResult is a file's fingerprint together with the profile it produced
+type CachedResult struct {
+	Size        int64          // Bytes
+	ModTime     int64          // Unix nanoseconds
+	ContentHash string         // SHA-256 of the file contents
+	Ruleset     string         // RulesetVersion at the time of analysis
+	Profile     RiskProfile    // ValueHashes are stripped before storing
+	Archive     *ArchiveResult // Set instead of Profile for archives and mail files
+}
+
+// identifiesPeople reports whether the result counted individuals, whose
+// value hashes are not persisted
+func (r CachedResult) identifiesPeople() bool {
+	return r.Profile.UniqueIndividuals > 0 || r.Archive != nil && r.Archive.identifiesPeople()
+}
+
+// ResultCache persists per-file results between scans so unchanged files
+// are not re-extracted. Implementations must be safe for concurrent use.
+type ResultCache interface {
+	Lookup(path string) (CachedResult, bool)
+	Store(path string, result CachedResult) error
+}
+
+// RulesetVersion identifies everything that influences a RiskProfile: the
+// engine and extractor versions, registered detectors and their definitions,
+// context rules and liability model. Changing any of them invalidates cached
+// results.
+func (e *RiskEngine) RulesetVersion() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "engine:%d:%d\n", EngineVersion, content.Version)
+	for _, d := range e.registry.Detectors() {
+		fmt.Fprintf(h, "detector:%s:%d:%d\n", d.Name(), d.Identifier(), d.Weight())
+		if def, ok := d.(Definer); ok {
+			fmt.Fprintf(h, "definition:%q\n", def.Definition())
+		}
+	}
+	e.scorer.mu.RLock()
+	rules, _ := json.Marshal(e.scorer.rules) // map keys marshal sorted
+	fmt.Fprintf(h, "context:%d:%s\n", e.scorer.window, rules)
+	e.scorer.mu.RUnlock()
+	fmt.Fprintf(h, "liability:%s\n", e.LiabilityModel().Version)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// analyzeCached returns the cached profile when the file's size and mtime (or,
+// failing that, its content hash) match an entry from the same ruleset, and
+// analyzes and caches it otherwise. The bool reports a cache hit; files that
+// could not be analyzed are returned with their error and not cached.
+//
+// Only files that identify no one are served from the cache. A file naming
+// people is re-read on every scan, however large: the value hashes that tell
+// individuals apart are salted per process and never persisted, and without
+// them its people could not be de-duplicated against the rest of the scan.
+//
+// The fingerprint is taken before the analysis, so an edit made while it
+// runs is never stored under the old result.
+func (e *RiskEngine) analyzeCached(path string, cache ResultCache, ruleset string) (RiskProfile, bool, error) {
+	size, modTime, err := statFile(path)
+	if err != nil {
+		profile, err := e.AnalyzeFileRisk(path)
+		return profile, false, err
+	}
+	entry, hash, ok := cachedEntry(path, cache, ruleset, size, modTime)
+	if ok && entry.Archive == nil {
+		return entry.Profile, true, nil
+	}
+	if hash == "" {
+		hash, _ = hashFile(path)
+	}
+
+	profile, err := e.AnalyzeFileRisk(path)
+	if err != nil {
+		return profile, false, err
+	}
+	storeCached(path, cache, hash, CachedResult{Size: size, ModTime: modTime, Ruleset: ruleset, Profile: withoutValueHashes(profile)})
+	return profile, false, nil
+}
+
+// analyzeArchiveCached is analyzeCached for archives and mail files, keyed
+// by the container's fingerprint
+func (e *RiskEngine) analyzeArchiveCached(path string, limits content.ArchiveLimits, cache ResultCache, ruleset string) (ArchiveResult, bool, error) {
+	size, modTime, err := statFile(path)
+	if err != nil {
+		archive, err := e.AnalyzeArchive(path, limits)
+		return archive, false, err
+	}
+	entry, hash, ok := cachedEntry(path, cache, ruleset, size, modTime)
+	if ok && entry.Archive != nil {
+		return *entry.Archive, true, nil
+	}
+	if hash == "" {
+		hash, _ = hashFile(path)
+	}
+
+	archive, err := e.AnalyzeArchive(path, limits)
+	if err != nil {
+		return archive, false, err
+	}
+	stored := archive.withoutValueHashes()
+	storeCached(path, cache, hash, CachedResult{Size: size, ModTime: modTime, Ruleset: ruleset, Archive: &stored})
+	return archive, false, nil
+}
+
+// cachedEntry returns the entry for this version of the file: same ruleset
+// and size, and the same mtime or, for a file that was only touched, the
+// same contents. The content hash is returned when it had to be computed.
+func cachedEntry(path string, cache ResultCache, ruleset string, size, modTime int64) (CachedResult, string, bool) {
+	entry, ok := cache.Lookup(path)
+	if !ok || entry.Ruleset != ruleset || entry.Size != size || entry.identifiesPeople() {
+		return CachedResult{}, "", false
+	}
+	if entry.ModTime == modTime {
+		return entry, "", true
+	}
+	hash, err := hashFile(path)
+	if err == nil && hash == entry.ContentHash {
+		entry.ModTime = modTime
+		cache.Store(path, entry)
+		return entry, hash, true
+	}
+	return CachedResult{}, hash, false
+}
+
+// storeCached caches entry under the content hash taken before its analysis;
+// without one the result is not cached
+func storeCached(path string, cache ResultCache, hash string, entry CachedResult) {
+	if hash == "" {
+		return
+	}
+	entry.ContentHash = hash
+	if err := cache.Store(path, entry); err != nil {
+		fmt.Printf("[Scan] Failed to cache result for %s: %v\n", path, err)
+	}
+}
+
+// withoutValueHashes copies a profile for persisting: value hashes are salted
+// per process and must never leave memory
+func withoutValueHashes(profile RiskProfile) RiskProfile {
+	stored := profile
+	stored.Details = make([]Finding, len(profile.Details))
+	for i, f := range profile.Details {
+		f.ValueHash = ""
+		stored.Details[i] = f
+	}
+	return stored
+}
+
+// statFile returns the size and modification time that identify a version of
+// a file for the cache and checkpoints
+func statFile(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return info.Size(), info.ModTime().UnixNano(), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,277 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+
+	"hipaa-app/internal/content"
+)
+
+// ColumnFinding reports a spreadsheet/CSV column that holds PHI
+type ColumnFinding struct {
+	Sheet      string `json:"sheet"`      // Sheet name ("" for CSV)
+	Column     int    `json:"column"`     // 1-based column number
+	Header     string `json:"header"`     // Header text as it appears in the file
+	Detector   string `json:"detector"`   // Detector the column maps to, e.g. "SSN"
+	Identifier int    `json:"identifier"` // HIPAA Safe Harbor identifier number
+	Category   string `json:"category"`   // Identifier category name
+	Rows       int    `json:"rows"`       // Rows holding PHI in this column
+	Source     string `json:"source"`     // "header" (named as PHI) or "content" (values matched)
+}
+
+// headerSemantic is the detector a column heading implies
+type headerSemantic struct {
+	detector   string
+	identifier int
+}
+
+// columnHeaders maps normalized column headings to the identifier they hold.
+// A match marks the whole column as PHI even when individual cells (Excel
+// serial dates, bare surnames, internal MRNs) would slip past the detectors.
+var columnHeaders = map[string]headerSemantic{}
+
+func init() {
+	add := func(detector string, identifier int, headers ...string) {
+		for _, h := range headers {
+			columnHeaders[h] = headerSemantic{detector: detector, identifier: identifier}
+		}
+	}
+	add("SSN", 7, "ssn", "ss#", "ss no", "ssn#", "social", "social security", "social security number", "social security no", "patient ssn", "member ssn", "subscriber ssn")
+	add("Person Name", 1, "name", "patient", "patient name", "full name", "first name", "last name", "firstname", "lastname",
+		"first", "last", "middle name", "member name", "client name", "guarantor", "guarantor name", "subscriber name",
+		"insured name", "pt name", "resident name", "emergency contact", "mother maiden name", "maiden name")
+	add("Date", 3, "dob", "d o b", "date of birth", "birth date", "birthdate", "birthday", "date of death", "dod",
+		"admit date", "admission date", "discharge date", "date of service", "dos", "service date", "visit date", "deceased date")
+	add("Medical Record Number", 8, "mrn", "mrn#", "medical record", "medical record number", "medical record no", "chart", "chart number", "chart no", "chart#", "patient id", "patient number", "patient no", "patient#", "pt id")
+	add("Health Plan Member ID", 9, "member id", "member number", "member no", "subscriber id", "subscriber number", "policy number", "policy no", "insurance id", "group and member id",
+		"medicaid id", "medicaid number", "medicare id", "medicare number", "mbi", "hicn", "beneficiary id")
+	add("Phone/Fax Number", 4, "phone", "phone number", "telephone", "tel", "cell", "cell phone", "mobile", "mobile phone", "home phone", "work phone", "fax", "fax number", "contact number")
+	add("Email", 6, "email", "e mail", "email address", "e mail address", "patient email")
+	add("Address", 2, "address", "address1", "address2", "address 1", "address 2", "street", "street address", "home address", "mailing address", "city", "county")
+	add("ZIP Code", 2, "zip", "zip code", "zipcode", "postal code", "postcode")
+	add("Account Number", 10, "account", "account number", "account no", "acct", "acct no", "acct#", "account#", "billing account")
+	add("License/ID Number", 11, "license", "license number", "drivers license", "driver license", "dl", "dl#", "dl number", "state id")
+	add("NPI", 18, "npi", "npi number")
+	add("IP Address", 15, "ip", "ip address")
+	add("Vehicle ID", 12, "vin", "vehicle id", "license plate", "plate number")
+	add("Credit Card", 0, "card number", "credit card", "credit card number", "cc number", "cc#")
+}
+
+const (
+	// headerConfidence is applied to cells that only the column header flags
+	headerConfidence = 0.9
+	// columnSampleSize bounds how many cells are sampled to infer an unnamed column
+	columnSampleSize = 100
+	// defaultColumnWeight is used when a header maps to a detector that is not registered
+	defaultColumnWeight = 8
+)
+
+// normalizeHeader lower-cases a heading and folds _ - . into spaces
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.Trim(strings.TrimSpace(h), `"'*:`))
+	h = strings.NewReplacer("_", " ", "-", " ", ".", " ", "/", " ").Replace(h)
+	return strings.Join(strings.Fields(h), " ")
+}
+
+// AnalyzeColumns reports the PHI columns of parsed tables
+func (e *RiskEngine) AnalyzeColumns(tables []content.Table) []ColumnFinding {
+	columns, _ := e.analyzeColumns(tables, individualSet{})
+	return columns
+}
+
+// analyzeColumns returns per-column findings and the extra score weight from
+// cells that only the header identifies (cells a detector already matches
+// are scored by the text pass and are not counted twice). Those header-only
+// cells are also added to people when the column identifies a person.
+func (e *RiskEngine) analyzeColumns(tables []content.Table, people individualSet) ([]ColumnFinding, float64) {
+	detectors := make(map[string]Detector)
+	for _, d := range e.registry.Detectors() {
+		detectors[d.Name()] = d
+	}
+
+	var findings []ColumnFinding
+	weighted := 0.0
+
+	for _, t := range tables {
+		for col, header := range t.Header {
+			cells := columnCells(t, col)
+			if len(cells) == 0 {
+				continue
+			}
+
+			if sem, ok := columnHeaders[normalizeHeader(header)]; ok {
+				weight := defaultColumnWeight
+				d := detectors[sem.detector]
+				if d != nil {
+					weight = d.Weight()
+				}
+				for _, value := range cells {
+					if d == nil || len(d.Find(value)) == 0 {
+						weighted += float64(weight) * headerConfidence
+						if individualDetectors[sem.detector] {
+							people.add(sem.detector, hashValue(e.salt, identityKey(sem.detector, value)))
+						}
+					}
+				}
+				findings = append(findings, ColumnFinding{
+					Sheet:      t.Sheet,
+					Column:     col + 1,
+					Header:     header,
+					Detector:   sem.detector,
+					Identifier: sem.identifier,
+					Category:   IdentifierCategory(sem.identifier),
+					Rows:       len(cells),
+					Source:     "header",
+				})
+				continue
+			}
+
+			// Unnamed column: flag it when most sampled cells match one detector
+			if d, _ := dominantDetector(e.registry.Detectors(), cells); d != nil {
+				rows := 0
+				for _, value := range cells {
+					if len(d.Find(value)) > 0 {
+						rows++
+					}
+				}
+				findings = append(findings, ColumnFinding{
+					Sheet:      t.Sheet,
+					Column:     col + 1,
+					Header:     header,
+					Detector:   d.Name(),
+					Identifier: d.Identifier(),
+					Category:   IdentifierCategory(d.Identifier()),
+					Rows:       rows,
+					Source:     "content",
+				})
+			}
+		}
+	}
+	return findings, weighted
+}
+
+// dominantDetector returns the detector matching at least half of a sample
+// of the column's cells, preferring the one with the most hits
+func dominantDetector(detectors []Detector, cells []string) (Detector, int) {
+	sample := cells
+	if len(sample) > columnSampleSize {
+		sample = sample[:columnSampleSize]
+	}
+
+	var best Detector
+	bestHits := 0
+	for _, d := range detectors {
+		hits := 0
+		for _, value := range sample {
+			if len(d.Find(value)) > 0 {
+				hits++
+			}
+		}
+		if hits > bestHits && hits*2 >= len(sample) {
+			best, bestHits = d, hits
+		}
+	}
+	return best, bestHits
+}
+
+// columnCells returns the non-empty values of one column
+func columnCells(t content.Table, col int) []string {
+	var cells []string
+	for _, row := range t.Rows {
+		if col < len(row) && strings.TrimSpace(row[col]) != "" {
+			cells = append(cells, row[col])
+		}
+	}
+	return cells
+}
+
+// summarizeColumns renders column findings as human readable strings
+func summarizeColumns(columns []ColumnFinding) []string {
+	var out []string
+	for _, c := range columns {
+		where := fmt.Sprintf("Column %d %q", c.Column, c.Header)
+		if c.Sheet != "" {
+			where = fmt.Sprintf("%s (sheet %s)", where, c.Sheet)
+		}
+		out = append(out, fmt.Sprintf("%s: %d row(s) of %s", where, c.Rows, c.Category))
+	}
+	return out
+}
+
+// RedactTable writes a cleaned copy of a CSV/XLSX file to outPath. Columns
+// whose header marks them as PHI are blanked (in Safe Harbor mode a cell is
+// generalized instead when the detector can, e.g. a DOB keeps its year);
+// every other cell is redacted like free text. The output stays a valid
+// file of the same format.
+func (e *RiskEngine) RedactTable(path, outPath string, mode RedactionMode) error {
+	tables, err := content.ExtractTables(path)
+	if err != nil {
+		return err
+	}
+
+	detectors := make(map[string]Detector)
+	for _, d := range e.registry.Detectors() {
+		detectors[d.Name()] = d
+	}
+	phi := make(map[string]map[int]Detector)
+	for _, c := range e.AnalyzeColumns(tables) {
+		if c.Source != "header" {
+			continue
+		}
+		if phi[c.Sheet] == nil {
+			phi[c.Sheet] = make(map[int]Detector)
+		}
+		phi[c.Sheet][c.Column-1] = detectors[c.Detector]
+	}
+
+	return content.RewriteTable(path, outPath, func(sheet string, col int, value string) string {
+		d, isPHI := phi[sheet][col]
+		if !isPHI {
+			return string(e.RedactContentMode([]byte(value), mode))
+		}
+		if g, ok := d.(Generalizer); ok && mode == RedactSafeHarbor {
+			if generalized := g.Generalize(value); generalized != value {
+				return generalized
+			}
+		}
+		return ""
+	})
+}
+
+// BlankColumns writes a copy of a CSV/XLSX file to outPath with the named
+// columns (matched case-insensitively on any sheet) emptied and everything
+// else left as is. It fails if none of the headers exist.
+func (e *RiskEngine) BlankColumns(path, outPath string, headers []string) error {
+	tables, err := content.ExtractTables(path)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for _, h := range headers {
+		wanted[normalizeHeader(h)] = true
+	}
+	blank := make(map[string]map[int]bool)
+	found := false
+	for _, t := range tables {
+		for col, h := range t.Header {
+			if wanted[normalizeHeader(h)] {
+				if blank[t.Sheet] == nil {
+					blank[t.Sheet] = make(map[int]bool)
+				}
+				blank[t.Sheet][col] = true
+				found = true
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("no matching columns for %s", strings.Join(headers, ", "))
+	}
+
+	return content.RewriteTable(path, outPath, func(sheet string, col int, value string) string {
+		if blank[sheet][col] {
+			return ""
+		}
+		return value
+	})
+}
@@ -0,0 +1,232 @@
+package risk
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ContextRule describes the words around a match that make it more or less
+// likely to be a real identifier
+type ContextRule struct {
+	Support []string // e.g. "zip", "dob" - confirm the hit
+	Negate  []string // e.g. "invoice", "order", "version" - explain the hit away
+	Base    float64  // multiplier applied when neither kind of keyword is nearby (1.0 = neutral)
+}
+
+const (
+	// defaultContextWindow is how many tokens on each side of a match are inspected
+	defaultContextWindow = 6
+	// contextScanBytes bounds how far from a match we look for those tokens
+	// (the window also stops at line breaks so table rows don't bleed together)
+	contextScanBytes = 160
+	// Confidence multipliers for supporting and negating cues
+	supportBoost  = 1.25
+	negatePenalty = 0.25
+	minConfidence = 0.05
+	// confirmedConfidence is the adjusted confidence a match needs to count
+	// as confirmed. A match with a nearby negating cue falls below it: it is
+	// still a finding, at its lowered confidence, but does not count as an
+	// SSN for the medical-record and public-folder boosts.
+	confirmedConfidence = 0.3
+)
+
+var contextTokenRegex = regexp.MustCompile(`[a-z0-9#$]+(?:[.'][a-z0-9]+)*`)
+
+// ContextScorer adjusts per-match confidence from the keywords found in a
+// token window around each match. The nearest cue wins: "DOB: 03/04/1950"
+// is boosted while "Version 3/4/2020" is suppressed.
+type ContextScorer struct {
+	mu     sync.RWMutex
+	window int
+	rules  map[string]ContextRule // by detector name
+}
+
+// NewContextScorer returns a scorer with the built-in rules for the stock detectors
+func NewContextScorer() *ContextScorer {
+	c := &ContextScorer{
+		window: defaultContextWindow,
+		rules:  make(map[string]ContextRule),
+	}
+	for name, rule := range defaultContextRules() {
+		c.rules[name] = rule
+	}
+	return c
+}
+
+// SetRule adds or replaces the context rule for a detector
+func (c *ContextScorer) SetRule(detector string, rule ContextRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules[detector] = rule
+}
+
+// SetWindow changes how many tokens on each side of a match are inspected
+func (c *ContextScorer) SetWindow(tokens int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tokens > 0 {
+		c.window = tokens
+	}
+}
+
+// Adjust returns the confidence of m after applying the detector's context rule
+func (c *ContextScorer) Adjust(detector, text string, m Match) float64 {
+	c.mu.RLock()
+	rule, ok := c.rules[detector]
+	window := c.window
+	c.mu.RUnlock()
+
+	if !ok {
+		return m.Confidence
+	}
+
+	before, after := contextTokens(text, m, window)
+	supportDist := nearestKeyword(before, after, rule.Support)
+	negateDist := nearestKeyword(before, after, rule.Negate)
+
+	confidence := m.Confidence
+	switch {
+	case supportDist >= 0 && (negateDist < 0 || supportDist <= negateDist):
+		confidence *= supportBoost
+	case negateDist >= 0:
+		confidence *= negatePenalty
+	case rule.Base > 0:
+		confidence *= rule.Base
+	}
+
+	if confidence > 1 {
+		confidence = 1
+	}
+	if confidence < minConfidence {
+		confidence = minConfidence
+	}
+	return confidence
+}
+
+// contextTokens returns up to window lowercase tokens before (nearest first)
+// and after (nearest first) the match
+func contextTokens(text string, m Match, window int) (before, after []string) {
+	start := m.Start - contextScanBytes
+	if start < 0 {
+		start = 0
+	}
+	if nl := strings.LastIndexByte(text[start:m.Start], '\n'); nl >= 0 {
+		start += nl + 1
+	}
+	end := m.End + contextScanBytes
+	if end > len(text) {
+		end = len(text)
+	}
+	if nl := strings.IndexByte(text[m.End:end], '\n'); nl >= 0 {
+		end = m.End + nl
+	}
+
+	prev := contextTokenRegex.FindAllString(strings.ToLower(text[start:m.Start]), -1)
+	for i := len(prev) - 1; i >= 0 && len(before) < window; i-- {
+		before = append(before, prev[i])
+	}
+	next := contextTokenRegex.FindAllString(strings.ToLower(text[m.End:end]), -1)
+	for i := 0; i < len(next) && len(after) < window; i++ {
+		after = append(after, next[i])
+	}
+	return before, after
+}
+
+// nearestKeyword returns the token distance to the closest keyword, or -1.
+// Multi-word keywords ("date of birth") must appear as consecutive tokens.
+func nearestKeyword(before, after []string, keywords []string) int {
+	best := -1
+	consider := func(d int) {
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+
+	for _, kw := range keywords {
+		words := strings.Fields(strings.ToLower(kw))
+		if len(words) == 0 {
+			continue
+		}
+		// before is nearest-first, so the phrase reads right-to-left there
+		for i := 0; i+len(words) <= len(before); i++ {
+			matched := true
+			for j, w := range words {
+				if before[i+len(words)-1-j] != w {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				consider(i)
+			}
+		}
+		for i := 0; i+len(words) <= len(after); i++ {
+			matched := true
+			for j, w := range words {
+				if after[i+j] != w {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				consider(i)
+			}
+		}
+	}
+	return best
+}
+
+// defaultContextRules are the built-in keyword cues per detector name
+func defaultContextRules() map[string]ContextRule {
+	commerce := []string{"invoice", "inv", "order", "po", "sku", "item", "qty", "quantity", "part", "tracking", "confirmation", "ref", "reference", "receipt", "ticket"}
+	software := []string{"version", "ver", "v", "build", "release", "revision", "rev", "copyright", "checksum"}
+
+	return map[string]ContextRule{
+		"SSN": {
+			Support: []string{"ssn", "ss#", "social", "social security", "ssa", "tin"},
+			Negate:  append(append([]string{}, commerce...), "routing", "tracking"),
+			Base:    1.0,
+		},
+		"Credit Card": {
+			Support: []string{"card", "cc", "visa", "mastercard", "amex", "discover", "credit", "debit", "exp", "cvv"},
+			Negate:  append([]string{}, commerce...),
+			Base:    1.0,
+		},
+		"Phone/Fax Number": {
+			Support: []string{"phone", "tel", "telephone", "fax", "cell", "mobile", "call", "contact", "home", "work"},
+			Negate:  append(append([]string{}, commerce...), "id", "account", "acct"),
+			Base:    0.9,
+		},
+		"NPI": {
+			Support: []string{"npi", "provider", "prescriber", "physician", "rendering", "referring", "billing provider"},
+			Negate:  append([]string{}, commerce...),
+			Base:    0.7,
+		},
+		"Medical Record Number": {
+			Support: []string{"mrn", "medical record", "chart", "record", "patient"},
+			Negate:  append(append([]string{}, commerce...), software...),
+			Base:    0.9,
+		},
+		"Date": {
+			Support: []string{"dob", "birth", "date of birth", "born", "admitted", "admission", "discharged", "discharge", "deceased", "death", "died", "dos", "date of service", "visit", "appointment", "surgery", "procedure", "seen"},
+			Negate:  append(append([]string{}, software...), "printed", "generated", "updated", "modified", "created", "effective", "revised", "page", "due", "invoice"),
+			Base:    0.7,
+		},
+		"ZIP Code": {
+			Support: []string{"zip", "zipcode", "postal", "postal code", "address", "city", "state"},
+			Negate:  append(append(append([]string{}, commerce...), software...), "$", "usd", "amount", "total", "id", "no", "#"),
+			Base:    0.5,
+		},
+		"IP Address": {
+			Support: []string{"ip", "host", "client", "remote", "address", "login", "from"},
+			Negate:  append([]string{}, software...),
+			Base:    0.9,
+		},
+		"Vehicle ID": {
+			Support: []string{"vin", "vehicle", "car", "auto", "plate"},
+			Negate:  append(append([]string{}, commerce...), software...),
+			Base:    0.6,
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package risk
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedactionMode selects how RedactContent treats identifiers
+type RedactionMode string
+
+const (
+	// RedactFull replaces every identifier with a placeholder
+	RedactFull RedactionMode = "full"
+	// RedactSafeHarbor keeps what the HIPAA Safe Harbor method allows:
+	// the year of a date and ages aggregated to "90+", with years that could
+	// reveal an age over 89 aggregated as well
+	RedactSafeHarbor RedactionMode = "safe_harbor"
+)
+
+// Generalizer is implemented by detectors that can coarsen a value instead of
+// removing it. RedactContentMode calls it in RedactSafeHarbor mode.
+type Generalizer interface {
+	Generalize(text string) string
+}
+
+const monthPattern = `(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|June?|July?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)`
+
+var (
+	numericDateRegex = regexp.MustCompile(`\b(\d{1,2})[/-](\d{1,2})[/-](\d{4}|\d{2})\b`)
+	isoDateRegex     = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?\b`)
+	// "March 3, 2021", "Mar. 3rd 2021"
+	monthDayYearRegex = regexp.MustCompile(`(?i:\b` + monthPattern + `\.?[ \t]+\d{1,2}(?:st|nd|rd|th)?,?[ \t]+(\d{4}))\b`)
+	// "3 Mar 2021", "3rd of March, 2021"
+	dayMonthYearRegex = regexp.MustCompile(`(?i:\b\d{1,2}(?:st|nd|rd|th)?[ \t]+(?:of[ \t]+)?` + monthPattern + `\.?,?[ \t]+(\d{4}))\b`)
+	// "March 2021" (month and year still identify under Safe Harbor)
+	monthYearRegex = regexp.MustCompile(`(?i:\b` + monthPattern + `\.?,?[ \t]+(\d{4}))\b`)
+	yearRegex      = regexp.MustCompile(`\d{4}$`)
+)
+
+// DateDetector finds dates directly related to an individual (HIPAA #3):
+// numeric (03/04/1950), ISO 8601 (1950-03-04) and written ("March 3, 2021",
+// "3 Mar 2021") forms. In Safe Harbor mode only the year is kept.
+type DateDetector struct{}
+
+// NewDateDetector creates the date detector
+func NewDateDetector() Detector {
+	return &DateDetector{}
+}
+
+func (d *DateDetector) Name() string    { return "Date" }
+func (d *DateDetector) Identifier() int { return 3 }
+func (d *DateDetector) Weight() int     { return 10 }
+
+func (d *DateDetector) Find(text string) []Match {
+	var matches []Match
+	add := func(start, end int, confidence float64) {
+		matches = append(matches, Match{Start: start, End: end, Value: text[start:end], Confidence: confidence})
+	}
+
+	for _, loc := range numericDateRegex.FindAllStringSubmatchIndex(text, -1) {
+		a, _ := strconv.Atoi(text[loc[2]:loc[3]])
+		b, _ := strconv.Atoi(text[loc[4]:loc[5]])
+		// Either MM/DD or DD/MM is fine, but one of them must be a month
+		if a < 1 || b < 1 || a > 31 || b > 31 || (a > 12 && b > 12) {
+			continue
+		}
+		add(loc[0], loc[1], 1.0)
+	}
+	for _, loc := range isoDateRegex.FindAllStringSubmatchIndex(text, -1) {
+		month, _ := strconv.Atoi(text[loc[4]:loc[5]])
+		day, _ := strconv.Atoi(text[loc[6]:loc[7]])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			continue
+		}
+		add(loc[0], loc[1], 1.0)
+	}
+	for _, loc := range monthDayYearRegex.FindAllStringIndex(text, -1) {
+		add(loc[0], loc[1], 1.0)
+	}
+	for _, loc := range dayMonthYearRegex.FindAllStringIndex(text, -1) {
+		add(loc[0], loc[1], 1.0)
+	}
+	for _, loc := range monthYearRegex.FindAllStringIndex(text, -1) {
+		add(loc[0], loc[1], 0.8)
+	}
+
+	return mergeOverlapping(text, matches)
+}
+
+func (d *DateDetector) Redact(text string) string {
+	return replaceMatches(text, d.Find(text), func(Match) string { return "[REDACTED-DATE]" })
+}
+
+// Generalize replaces each date with its year. A year 90 or more years ago
+// could reveal an age over 89, so those are aggregated into "1936 or
+// earlier" (in 2026) as Safe Harbor requires.
+func (d *DateDetector) Generalize(text string) string {
+	cutoff := time.Now().Year() - 90
+	return replaceMatches(text, d.Find(text), func(m Match) string {
+		year := dateYear(m.Value)
+		if year == "" {
+			return "[REDACTED-DATE]"
+		}
+		if y, _ := strconv.Atoi(year); y <= cutoff {
+			return strconv.Itoa(cutoff) + " or earlier"
+		}
+		return year
+	})
+}
+
+// dateYear pulls the 4-digit year out of a matched date, expanding 2-digit
+// years to the most recent matching century
+func dateYear(value string) string {
+	if sm := isoDateRegex.FindStringSubmatch(value); sm != nil {
+		return sm[1]
+	}
+	if sm := numericDateRegex.FindStringSubmatch(value); sm != nil {
+		if len(sm[3]) == 4 {
+			return sm[3]
+		}
+		yy, _ := strconv.Atoi(sm[3])
+		century := time.Now().Year() / 100 * 100
+		if century+yy > time.Now().Year() {
+			century -= 100
+		}
+		return strconv.Itoa(century + yy)
+	}
+	return yearRegex.FindString(strings.TrimRight(value, " \t,."))
+}
+
+var ageRegex = regexp.MustCompile(`(?i:\b(?P<value>\d{2,3})[ \t-]*(?:years?|yrs?)[ \t-]*old\b|` +
+	`\b(?P<value>\d{2,3})[ \t]*(?:y/o|y\.o\.|yo)\b|` +
+	`\baged?[ \t]*:?[ \t]*(?P<value>\d{2,3})\b)`)
+
+// AgeDetector finds explicit ages over 89 ("92-year-old", "Age: 94"), which
+// Safe Harbor requires be aggregated into a single "90 or older" category.
+// Younger ages are not identifiers and are ignored.
+type AgeDetector struct{}
+
+// NewAgeDetector creates the age detector
+func NewAgeDetector() Detector {
+	return &AgeDetector{}
+}
+
+func (d *AgeDetector) Name() string    { return "Age Over 89" }
+func (d *AgeDetector) Identifier() int { return 3 }
+func (d *AgeDetector) Weight() int     { return 10 }
+
+func (d *AgeDetector) Find(text string) []Match {
+	var matches []Match
+	for _, loc := range ageRegex.FindAllStringSubmatchIndex(text, -1) {
+		// Exactly one of the "value" groups participates in each match
+		for g := 2; g < len(loc); g += 2 {
+			if loc[g] < 0 {
+				continue
+			}
+			age, _ := strconv.Atoi(text[loc[g]:loc[g+1]])
+			if age >= 90 && age <= 125 {
+				matches = append(matches, Match{Start: loc[g], End: loc[g+1], Value: text[loc[g]:loc[g+1]], Confidence: 1.0})
+			}
+			break
+		}
+	}
+	return matches
+}
+
+func (d *AgeDetector) Redact(text string) string {
+	return replaceMatches(text, d.Find(text), func(Match) string { return "[REDACTED-AGE]" })
+}
+
+// Generalize aggregates every age over 89 into "90+"
+func (d *AgeDetector) Generalize(text string) string {
+	return replaceMatches(text, d.Find(text), func(Match) string { return "90+" })
+}
@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDateGeneralizeAggregatesOldYears(t *testing.T) {
+	cutoff := time.Now().Year() - 90
+	d := &DateDetector{}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"DOB: 01/02/1930", "DOB: " + strconv.Itoa(cutoff) + " or earlier"},
+		{"DOB: 1925-07-04", "DOB: " + strconv.Itoa(cutoff) + " or earlier"},
+		{"Born March 3, " + strconv.Itoa(cutoff), "Born " + strconv.Itoa(cutoff) + " or earlier"},
+		{"Born March 3, " + strconv.Itoa(cutoff+1), "Born " + strconv.Itoa(cutoff+1)},
+		{"Seen 2021-03-04", "Seen 2021"},
+	}
+	for _, tt := range tests {
+		if got := d.Generalize(tt.text); got != tt.want {
+			t.Errorf("Generalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
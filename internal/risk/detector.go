@@ -0,0 +1,207 @@
+package risk
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Detector finds and redacts a single class of identifier.
+// Implementations must be safe for concurrent use.
+type Detector interface {
+	// Name is the human readable label used in findings ("SSN", "Email", ...)
+	Name() string
+	// Identifier is the HIPAA Safe Harbor identifier number (1-18), or 0
+	// for non-HIPAA identifiers such as PCI-DSS card numbers
+	Identifier() int
+	// Weight is the score contributed by each match
+	Weight() int
+	// Find returns every match in text, ordered by position.
+	// Matches vetoed by validation are not returned.
+	Find(text string) []Match
+	// Redact returns text with every match replaced by a placeholder
+	Redact(text string) string
+}
+
+// Definer is implemented by detectors built from data, such as a pattern
+// and validators. RulesetVersion hashes the definition, so editing a
+// built-in pattern or registering a changed one invalidates cached results.
+type Definer interface {
+	Definition() string
+}
+
+// tableReader is implemented by detectors that also recognize a table laid
+// out as delimited text. For a file whose tables were parsed, the columns
+// are scored in columns.go, so analyzeText calls findOutsideTables instead.
+type tableReader interface {
+	findOutsideTables(text string) []Match
+}
+
+// Match is a single detector hit within a piece of text
+type Match struct {
+	Start      int     // byte offset of the first matched byte
+	End        int     // byte offset just past the match
+	Value      string  // the matched text
+	Confidence float64 // 0.0 - 1.0, lowered by validators for doubtful hits
+}
+
+// regexDetector is a Detector backed by a single regular expression
+type regexDetector struct {
+	name        string
+	identifier  int
+	weight      int
+	pattern     *regexp.Regexp
+	placeholder string
+	validators  []Validator
+}
+
+// NewRegexDetector builds a Detector from a regular expression.
+// Every match of pattern is replaced with placeholder when redacting; if the
+// pattern has a capture group named "value", only that group is reported and
+// replaced so labels such as "Member ID:" survive redaction.
+// Optional validators run on each match and can veto or down-weight it.
+func NewRegexDetector(name string, identifier, weight int, pattern *regexp.Regexp, placeholder string, validators ...Validator) Detector {
+	return &regexDetector{
+		name:        name,
+		identifier:  identifier,
+		weight:      weight,
+		pattern:     pattern,
+		placeholder: placeholder,
+		validators:  validators,
+	}
+}
+
+func (d *regexDetector) Name() string    { return d.name }
+func (d *regexDetector) Identifier() int { return d.identifier }
+func (d *regexDetector) Weight() int     { return d.weight }
+
+// Definition is the pattern, placeholder and validators of the detector
+func (d *regexDetector) Definition() string {
+	def := d.pattern.String() + "\x00" + d.placeholder
+	for _, v := range d.validators {
+		def += "\x00" + runtime.FuncForPC(reflect.ValueOf(v).Pointer()).Name()
+	}
+	return def
+}
+
+func (d *regexDetector) Find(text string) []Match {
+	group := 0
+	if i := d.pattern.SubexpIndex("value"); i > 0 {
+		group = i
+	}
+
+	locs := d.pattern.FindAllStringSubmatchIndex(text, -1)
+	matches := make([]Match, 0, len(locs))
+	for _, all := range locs {
+		loc := all[2*group : 2*group+2]
+		if loc[0] < 0 {
+			continue
+		}
+		value := text[loc[0]:loc[1]]
+		confidence := applyValidators(d.validators, value)
+		if confidence <= 0 {
+			continue
+		}
+		matches = append(matches, Match{Start: loc[0], End: loc[1], Value: value, Confidence: confidence})
+	}
+	return matches
+}
+
+func (d *regexDetector) Redact(text string) string {
+	return replaceMatches(text, d.Find(text), func(Match) string { return d.placeholder })
+}
+
+// replaceMatches rewrites each match in text using replace.
+// Overlapping matches are skipped so that redaction never splits a placeholder.
+func replaceMatches(text string, matches []Match, replace func(Match) string) string {
+	if len(matches) == 0 {
+		return text
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.Start < last {
+			continue
+		}
+		sb.WriteString(text[last:m.Start])
+		sb.WriteString(replace(m))
+		last = m.End
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// mergeOverlapping sorts matches by position and collapses overlaps,
+// keeping the widest span and the highest confidence
+func mergeOverlapping(text string, matches []Match) []Match {
+	if len(matches) < 2 {
+		return matches
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	merged := []Match{matches[0]}
+	for _, m := range matches[1:] {
+		prev := &merged[len(merged)-1]
+		if m.Start >= prev.End {
+			merged = append(merged, m)
+			continue
+		}
+		if m.End > prev.End {
+			prev.End = m.End
+		}
+		if m.Confidence > prev.Confidence {
+			prev.Confidence = m.Confidence
+		}
+	}
+	for i := range merged {
+		merged[i].Value = text[merged[i].Start:merged[i].End]
+	}
+	return merged
+}
+
+// spanSet holds the spans claimed so far, sorted by start, with the furthest
+// end reached by any span up to each index so containment is a binary search
+type spanSet struct {
+	spans  []Match
+	maxEnd []int
+}
+
+// add merges matches into the set, keeping it sorted by start
+func (s *spanSet) add(matches []Match) {
+	if len(matches) == 0 {
+		return
+	}
+	added := append([]Match(nil), matches...)
+	sort.Slice(added, func(i, j int) bool { return added[i].Start < added[j].Start })
+
+	merged := make([]Match, 0, len(s.spans)+len(added))
+	i, j := 0, 0
+	for i < len(s.spans) || j < len(added) {
+		if j == len(added) || i < len(s.spans) && s.spans[i].Start <= added[j].Start {
+			merged = append(merged, s.spans[i])
+			i++
+		} else {
+			merged = append(merged, added[j])
+			j++
+		}
+	}
+	s.spans = merged
+	s.maxEnd = make([]int, len(merged))
+	for k, m := range merged {
+		s.maxEnd[k] = m.End
+		if k > 0 && s.maxEnd[k-1] > m.End {
+			s.maxEnd[k] = s.maxEnd[k-1]
+		}
+	}
+}
+
+// covers reports whether m lies entirely inside one of the spans
+func (s *spanSet) covers(m Match) bool {
+	// Spans starting at or before m; the furthest reaching one decides
+	n := sort.Search(len(s.spans), func(i int) bool { return s.spans[i].Start > m.Start })
+	return n > 0 && s.maxEnd[n-1] >= m.End
+}
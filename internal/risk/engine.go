@@ -1,23 +1,79 @@
 package risk
 
 import (
-	"bufio"
 	"context"
+	"crypto/rand"
 	"fmt"
-	"io/fs"
+	"math"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	
 	"hipaa-app/internal/content"
+	"hipaa-app/internal/liability"
 )
 
 type AuditReport struct {
-	TotalFiles     int           `json:"totalFiles"`
+	TotalFiles     int           `json:"totalFiles"`     // Files on disk that were read; an archive or mail file counts once
+	ArchiveMembers int           `json:"archiveMembers"` // Files read inside the archives and mail files among TotalFiles
 	TotalRiskScore int           `json:"totalRiskScore"`
-	PotentialLiability int       `json:"potentialLiability"`
+	PotentialLiability int       `json:"potentialLiability"` // Liability.Total
+	Liability      liability.Estimate `json:"liability"`  // Model, version and assumptions behind PotentialLiability
 	TopOffenders   []RiskProfile `json:"topOffenders"`
 	CriticalCount  int           `json:"criticalCount"`
+	TimedOutFiles  []string      `json:"timedOutFiles,omitempty"` // Counted in TotalFiles but not analyzed
+	CachedFiles    int           `json:"cachedFiles"`             // Unchanged files whose previous result was reused
+	ResumedFiles   int           `json:"resumedFiles"`            // Files finished by an interrupted earlier attempt
+	Unscanned      []UnscannedFile `json:"unscanned,omitempty"`   // Unsupported or unreadable; not counted in TotalFiles
+	SkippedBinaries map[string]int `json:"skippedBinaries,omitempty"` // Executables, media, fonts and other unreadable binaries by extension; not counted in TotalFiles
+	// Distinct people across all files (largest distinct count of any
+	// person-level identifier) and whether it reaches the HHS 500 threshold
+	EstimatedIndividuals int  `json:"estimatedIndividuals"`
+	NotifyHHS            bool `json:"notifyHHS"`
+
+	individuals individualSet // in-memory salted hashes backing EstimatedIndividuals
+	unnamed     int           // risky files with no person-level identifier (one record each)
+	model       liability.Model
+}
+
+// Merge folds another report (e.g. a second scan root) into r, de-duplicating
+// individuals across both and re-pricing the combined exposure
+func (r *AuditReport) Merge(other AuditReport) {
+	r.TotalFiles += other.TotalFiles
+	r.ArchiveMembers += other.ArchiveMembers
+	r.TotalRiskScore += other.TotalRiskScore
+	r.CriticalCount += other.CriticalCount
+	r.TopOffenders = append(r.TopOffenders, other.TopOffenders...)
+	r.TimedOutFiles = append(r.TimedOutFiles, other.TimedOutFiles...)
+	r.CachedFiles += other.CachedFiles
+	r.ResumedFiles += other.ResumedFiles
+	r.Unscanned = append(r.Unscanned, other.Unscanned...)
+	sortUnscanned(r.Unscanned)
+	r.SkippedBinaries = addCounts(r.SkippedBinaries, other.SkippedBinaries)
+
+	if r.individuals == nil {
+		r.individuals = individualSet{}
+	}
+	r.individuals.merge(other.individuals)
+	r.unnamed += other.unnamed
+	if r.model.Version == "" {
+		r.model = other.model
+	}
+	r.finalize()
+}
+
+// finalize derives the individual count and liability from the merged sets
+func (r *AuditReport) finalize() {
+	r.EstimatedIndividuals = r.individuals.estimate()
+	r.NotifyHHS = r.EstimatedIndividuals >= BreachNotificationThreshold
+	if r.model.Version == "" {
+		r.model = liability.Default()
+	}
+	// Caps apply per calendar year across the whole audit, so the total is
+	// priced once rather than summed from per-file estimates
+	r.Liability = r.model.Estimate(r.EstimatedIndividuals + r.unnamed)
+	r.PotentialLiability = r.Liability.Total
 }
 
 type RiskReport struct {
@@ -33,131 +89,112 @@ type RiskProfile struct {
 	HasDiagnosis   bool   `json:"hasDiagnosis"`
 	RiskLabel      string `json:"riskLabel"` // "Low", "Medium", "High", "CRITICAL"
 	EstimatedFine  int    `json:"estimatedFine"`
-	Findings       []string `json:"findings"`
+	Findings       []string `json:"findings"` // Human readable summary derived from Details
+	Details        []Finding `json:"details"`
+	Columns        []ColumnFinding `json:"columns,omitempty"` // CSV/XLSX columns holding PHI
+	UniqueIndividuals int     `json:"uniqueIndividuals"` // Distinct people identified in this file
+
+	individuals individualSet // in-memory salted hashes backing UniqueIndividuals
 }
 
 type RiskEngine struct {
-	// HIPAA Identifier Patterns
-	ssnRegex     *regexp.Regexp // #7
-	ccRegex      *regexp.Regexp // PCI-DSS
-	icdRegex     *regexp.Regexp
-	phoneRegex   *regexp.Regexp // #4, #5
-	emailRegex   *regexp.Regexp // #6
-	mrnRegex     *regexp.Regexp // #8
-	zipRegex     *regexp.Regexp // #2
-	dateRegex    *regexp.Regexp // #3
-	ipRegex      *regexp.Regexp // #15
-	urlRegex     *regexp.Regexp // #14
-	accountRegex *regexp.Regexp // #10
-	licenseRegex *regexp.Regexp // #11
-	vinRegex     *regexp.Regexp // #12
+	// HIPAA identifier detectors, run in registration order
+	registry *Registry
+	// Adjusts per-match confidence from surrounding keywords
+	scorer *ContextScorer
+	// Per-process salt for Finding.ValueHash (never persisted)
+	salt []byte
+
+	mu       sync.RWMutex
+	model    liability.Model // prices EstimatedFine and PotentialLiability
+	scanOpts ScanOptions     // worker pool settings for AnalyzeDirectory
 }
 
 func NewRiskEngine() *RiskEngine {
+	return NewRiskEngineWithRegistry(DefaultRegistry())
+}
+
+// NewRiskEngineWithRegistry creates an engine that runs a caller-supplied detector set
+func NewRiskEngineWithRegistry(registry *Registry) *RiskEngine {
+	salt := make([]byte, 32)
+	rand.Read(salt)
+
 	return &RiskEngine{
-		// #7: SSN - 123-45-6789 or 123456789
-		ssnRegex: regexp.MustCompile(`\b\d{3}-?\d{2}-?\d{4}\b`),
-		
-		// PCI-DSS: Credit Cards
-		ccRegex: regexp.MustCompile(`\b\d{4}[\s-]?\d{4}[\s-]?\d{4}[\s-]?\d{4}\b`),
-		
-		// #4, #5: Phone/Fax - (555) 123-4567, 555-123-4567, 555.123.4567
-		phoneRegex: regexp.MustCompile(`\b(?:\+?1[\s.-]?)?\(?\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{4}\b`),
-		
-		// #6: Email addresses
-		emailRegex: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}\b`),
-		
-		// #8: MRN - Medical Record Numbers (various formats)
-		mrnRegex: regexp.MustCompile(`\b(?:MRN|M\.?R\.?N\.?)[:\s#]*[A-Z0-9]{6,12}\b|\b[A-Z]{2,3}\d{6,9}\b`),
-		
-		// #2: ZIP codes (US 5 or 9 digit)
-		zipRegex: regexp.MustCompile(`\b\d{5}(?:-\d{4})?\b`),
-		
-		// #3: Dates - MM/DD/YYYY, MM-DD-YYYY, DOB: etc.
-		dateRegex: regexp.MustCompile(`\b(?:DOB|Date of Birth|Admitted|Discharged|Born|D\.O\.B\.?)\s*:?\s*\d{1,2}[/-]\d{1,2}[/-]\d{2,4}\b|\b\d{1,2}[/-]\d{1,2}[/-]\d{2,4}\b`),
-		
-		// #15: IP Addresses (IPv4)
-		ipRegex: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
-		
-		// #14: URLs
-		urlRegex: regexp.MustCompile(`\b(?:https?://|www\.)[-A-Za-z0-9+&@#/%?=~_|!:,.;]*[-A-Za-z0-9+&@#/%=~_|]`),
-		
-		// #10: Account Numbers (generic pattern)
-		accountRegex: regexp.MustCompile(`\b(?:Account|Acct|Patient)\s*#?:?\s*[A-Z0-9]{6,15}\b`),
-		
-		// #11: License Numbers - DL, Driver License, etc.
-		licenseRegex: regexp.MustCompile(`\b(?:DL|Driver'?s? License|License)\s*#?:?\s*[A-Z0-9]{6,15}\b`),
-		
-		// #12: VIN (Vehicle Identification Number)
-		vinRegex: regexp.MustCompile(`\b[A-HJ-NPR-Z0-9]{17}\b`),
-		
-		icdRegex: regexp.MustCompile(`[A-Z]\d{2}\.[A-Z0-9]{1,2}`),
+		registry: registry,
+		scorer:   NewContextScorer(),
+		salt:     salt,
+		model:    liability.Default(),
+		scanOpts: DefaultScanOptions(),
 	}
 }
 
-// AnalyzeDirectory recursively scans a directory and returns an AuditReport
-func (e *RiskEngine) AnalyzeDirectory(ctx context.Context, rootPath string, progressCallback func(path string)) (AuditReport, error) {
-	report := AuditReport{
-		TopOffenders: []RiskProfile{},
+// SetLiabilityModel replaces the model used to price exposure
+func (e *RiskEngine) SetLiabilityModel(model liability.Model) error {
+	if err := model.Validate(); err != nil {
+		return err
 	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.model = model
+	return nil
+}
 
-	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-		
-		if err != nil {
-			return nil // Skip errors accessing files
-		}
-		if d.IsDir() {
-			return nil
-		}
-		
-		// Only scan supported file types
-		ext := strings.ToLower(filepath.Ext(path))
-		switch ext {
-		case ".txt", ".csv", ".log", ".md", ".json", ".xml", ".html", ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".rtf",
-			".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tiff", ".tif":
-			// Allowed
-		default:
-			return nil
-		}
+// LiabilityModel returns the model used to price exposure
+func (e *RiskEngine) LiabilityModel() liability.Model {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.model
+}
 
-		if progressCallback != nil {
-			progressCallback(path)
-		}
+// Registry exposes the engine's detectors so callers can add their own
+func (e *RiskEngine) Registry() *Registry {
+	return e.registry
+}
 
-		report.TotalFiles++
-		
-		profile, _ := e.AnalyzeFileRisk(path)
-		if profile.RiskScore > 0 {
-			report.TotalRiskScore += profile.RiskScore
-			report.PotentialLiability += profile.EstimatedFine
-			report.TopOffenders = append(report.TopOffenders, profile)
-			if profile.RiskLabel == "CRITICAL" {
-				report.CriticalCount++
-			}
-		}
+// ContextScorer exposes the keyword rules used to adjust match confidence
+func (e *RiskEngine) ContextScorer() *ContextScorer {
+	return e.scorer
+}
 
-		return nil
-	})
+// AnalyzeDirectory recursively scans a directory and returns an AuditReport.
+// Files are analyzed concurrently according to the engine's ScanOptions.
+func (e *RiskEngine) AnalyzeDirectory(ctx context.Context, rootPath string, progressCallback func(path string)) (AuditReport, error) {
+	return e.scanDirectory(ctx, rootPath, e.ScanOptions(), progressCallback)
+}
 
-	return report, err
+// AnalyzeDirectoryWithOptions scans with per-call options, e.g. a result cache
+func (e *RiskEngine) AnalyzeDirectoryWithOptions(ctx context.Context, rootPath string, opts ScanOptions, progressCallback func(path string)) (AuditReport, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultScanOptions().Workers
+	}
+	return e.scanDirectory(ctx, rootPath, opts, progressCallback)
 }
 
 
 func (e *RiskEngine) AnalyzeFileRisk(path string) (RiskProfile, error) {
 	// 1. Text Extraction (Supports PDF, DOCX, XLSX, etc.)
-	text, err := content.ExtractText(path)
+	doc, err := content.ExtractDocument(path)
 	if err != nil {
 		return RiskProfile{FilePath: path}, err
 	}
+	var tables []content.Table
+	if content.IsTabular(path) {
+		tables, _ = content.ExtractTables(path)
+	}
+	return e.analyzeText(path, doc, tables), nil
+}
+
+// analyzeText scores extracted text. path may be a virtual archive path;
+// tables carry the column structure of tabular files. Findings in a
+// multi-part document name their part and are numbered from its first line.
+func (e *RiskEngine) analyzeText(path string, doc content.Document, tables []content.Table) RiskProfile {
+	text := doc.Text
 
 	profile := RiskProfile{
 		FilePath: path,
 		Findings: []string{},
+		Details:  []Finding{},
+		individuals: individualSet{},
 	}
 
 	// 2. AI Classification (Offline Naive Bayes)
@@ -167,114 +204,90 @@ func (e *RiskEngine) AnalyzeFileRisk(path string) (RiskProfile, error) {
 		profile.Findings = append(profile.Findings, fmt.Sprintf("AI Analysis: %d%% likelihood of being %s Document", int(confidence), category))
 	}
 
-	// 3. Scan Lines (from memory buffer)
-	scanner := bufio.NewScanner(strings.NewReader(text))
-	
+	// 3. Run every detector over the full text, mapping offsets back to lines
 	sensitiveKeywords := []string{"hiv", "cancer", "psychotherapy", "suicide", "minor", "diagnosis", "patient"}
+	lines := newLineIndex(text)
 	
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		
-		// Hard Risks (Regex Detection for all HIPAA PHI)
-		
-		// SSN (#7)
-		ssns := e.ssnRegex.FindAllString(line, -1)
-		if len(ssns) > 0 {
-			profile.SSNCount += len(ssns)
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: %d SSN(s) found", lineNum, len(ssns)))
-		}
-
-		// Credit Cards (PCI-DSS)
-		ccs := e.ccRegex.FindAllString(line, -1)
-		if len(ccs) > 0 {
-			profile.RiskScore += len(ccs) * 10 
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: Credit Card found", lineNum))
-		}
-		
-		// Phone/Fax Numbers (#4, #5)
-		phones := e.phoneRegex.FindAllString(line, -1)
-		if len(phones) > 0 {
-			profile.RiskScore += len(phones) * 5
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: %d Phone/Fax number(s) found", lineNum, len(phones)))
-		}
-		
-		// Email Addresses (#6)
-		emails := e.emailRegex.FindAllString(line, -1)
-		if len(emails) > 0 {
-			profile.RiskScore += len(emails) * 5
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: %d Email(s) found", lineNum, len(emails)))
-		}
-		
-		// Medical Record Numbers (#8)
-		mrns := e.mrnRegex.FindAllString(line, -1)
-		if len(mrns) > 0 {
-			profile.RiskScore += len(mrns) * 15
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: Medical Record Number found", lineNum))
-		}
-		
-		// Dates (DOB, Admission, etc.) (#3)
-		dates := e.dateRegex.FindAllString(line, -1)
-		if len(dates) > 0 {
-			profile.RiskScore += len(dates) * 10
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: %d Date(s) found (DOB/Admission/Discharge)", lineNum, len(dates)))
-		}
-		
-		// IP Addresses (#15)
-		ips := e.ipRegex.FindAllString(line, -1)
-		if len(ips) > 0 {
-			profile.RiskScore += len(ips) * 3
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: IP Address found", lineNum))
-		}
-		
-		// URLs (#14)
-		urls := e.urlRegex.FindAllString(line, -1)
-		if len(urls) > 0 {
-			profile.RiskScore += len(urls) * 5
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: URL found", lineNum))
-		}
-		
-		// Account Numbers (#10)
-		accounts := e.accountRegex.FindAllString(line, -1)
-		if len(accounts) > 0 {
-			profile.RiskScore += len(accounts) * 10
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: Account Number found", lineNum))
-		}
-		
-		// License Numbers (#11)
-		licenses := e.licenseRegex.FindAllString(line, -1)
-		if len(licenses) > 0 {
-			profile.RiskScore += len(licenses) * 8
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: License/ID Number found", lineNum))
+	weighted := 0.0 // detector weights scaled by match confidence
+	
+	// Hard Risks (Detector registry covers all HIPAA PHI)
+	// A hit inside a span already claimed by an earlier detector is not
+	// counted again, mirroring how RedactContent applies detectors in order.
+	var claimed spanSet
+	for _, d := range e.registry.Detectors() {
+		var matches []Match
+		if tr, ok := d.(tableReader); ok && len(tables) > 0 {
+			matches = tr.findOutsideTables(text)
+		} else {
+			matches = d.Find(text)
 		}
-		
-		// VIN (#12)
-		vins := e.vinRegex.FindAllString(line, -1)
-		if len(vins) > 0 {
-			profile.RiskScore += len(vins) * 8
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: Vehicle ID found", lineNum))
+		for _, m := range matches {
+			if claimed.covers(m) {
+				continue
+			}
+			// Context only scales a hit; RedactContent removes it regardless
+			m.Confidence = e.scorer.Adjust(d.Name(), text, m)
+			if d.Name() == detectorSSN && m.Confidence >= confirmedConfidence {
+				profile.SSNCount++
+			}
+			weighted += float64(d.Weight()) * m.Confidence
+			
+			valueHash := hashValue(e.salt, identityKey(d.Name(), m.Value))
+			if individualDetectors[d.Name()] {
+				profile.individuals.add(d.Name(), valueHash)
+			}
+			
+			line, column := lines.position(m.Start)
+			part, inPart := doc.PartAt(m.Start)
+			if inPart {
+				first, _ := lines.position(part.Start)
+				line -= first - 1
+			}
+			profile.Details = append(profile.Details, Finding{
+				Part:       part.Name,
+				Detector:   d.Name(),
+				Identifier: d.Identifier(),
+				Category:   IdentifierCategory(d.Identifier()),
+				Line:       line,
+				Column:     column,
+				Start:      m.Start,
+				End:        m.End,
+				Confidence: m.Confidence,
+				ValueHash:  valueHash,
+			})
 		}
-		
-		// ZIP Codes (#2)
-		zips := e.zipRegex.FindAllString(line, -1)
-		if len(zips) > 0 {
-			profile.RiskScore += len(zips) * 3
-			profile.Findings = append(profile.Findings, fmt.Sprintf("Line %d: ZIP Code found", lineNum))
+		claimed.add(matches)
+	}
+	
+	// Tabular files: whole columns named as PHI ("SSN", "DOB", "MRN") count
+	// even where the cell values alone would not match
+	if len(tables) > 0 {
+		columns, columnWeight := e.analyzeColumns(tables, profile.individuals)
+		profile.Columns = columns
+		weighted += columnWeight
+	}
+	
+	// Soft Risks (Context)
+	lowerText := strings.ToLower(text)
+	for _, kw := range sensitiveKeywords {
+		if strings.Contains(lowerText, kw) {
+			profile.HasDiagnosis = true
+			break
 		}
+	}
 
-		// Soft Risks (Context)
-		lowerLine := strings.ToLower(line)
-		for _, kw := range sensitiveKeywords {
-			if strings.Contains(lowerLine, kw) {
-				profile.HasDiagnosis = true
-				break
-			}
-		}
+	profile.UniqueIndividuals = profile.individuals.estimate()
+	
+	// Derived per-line summary for the legacy string findings
+	sort.SliceStable(profile.Details, func(i, j int) bool { return profile.Details[i].Start < profile.Details[j].Start })
+	profile.Findings = append(profile.Findings, summarizeFindings(profile.Details)...)
+	profile.Findings = append(profile.Findings, summarizeColumns(profile.Columns)...)
+	if profile.UniqueIndividuals > 0 {
+		profile.Findings = append(profile.Findings, fmt.Sprintf("Estimated %d individual(s) identifiable", profile.UniqueIndividuals))
 	}
 
 	// 4. Scoring Logic (Boosted by AI)
-	score := profile.SSNCount * 10
+	score := int(math.Round(weighted))
 	
 	if profile.HasDiagnosis {
 		score += 50
@@ -308,14 +321,24 @@ func (e *RiskEngine) AnalyzeFileRisk(path string) (RiskProfile, error) {
 		profile.RiskLabel = "Safe"
 	}
 
-	profile.EstimatedFine = (profile.SSNCount * 100) + (profile.RiskScore * 50) 
+	// A risky file with no person-level identifier still exposes at least one record
+	if profile.RiskScore > 0 {
+		records := profile.UniqueIndividuals
+		if records == 0 {
+			records = 1
+		}
+		profile.EstimatedFine = e.LiabilityModel().Estimate(records).Total
+	}
 
-	return profile, nil
+	return profile
 }
 
 // Keep the old method for single file compatibility if needed, or deprecate
 // We update it to strict signature for older code but redirect to new logic
 func (e *RiskEngine) AnalyzeFile(path string) (RiskReport, error) {
+	if format, err := content.DetectFormat(path); err == nil && format.IsArchive() {
+		return e.analyzeArchiveFile(path)
+	}
 	profile, err := e.AnalyzeFileRisk(path)
 	return RiskReport{
 		RiskScore: profile.RiskScore,
@@ -325,21 +348,23 @@ func (e *RiskEngine) AnalyzeFile(path string) (RiskReport, error) {
 }
 // RedactContent replaces all HIPAA PHI identifiers with placeholders
 func (e *RiskEngine) RedactContent(content []byte) []byte {
+	return e.RedactContentMode(content, RedactFull)
+}
+
+// RedactContentMode redacts using the given mode. In RedactSafeHarbor mode,
+// detectors that implement Generalizer keep what Safe Harbor allows
+// (e.g. the year of a date) instead of removing the value outright.
+func (e *RiskEngine) RedactContentMode(content []byte, mode RedactionMode) []byte {
 	text := string(content)
 	
-	// Redact in order of specificity (most specific first)
-	text = e.ssnRegex.ReplaceAllString(text, "[REDACTED-SSN]")
-	text = e.ccRegex.ReplaceAllString(text, "[REDACTED-CC]")
-	text = e.phoneRegex.ReplaceAllString(text, "[REDACTED-PHONE]")
-	text = e.emailRegex.ReplaceAllString(text, "[REDACTED-EMAIL]")
-	text = e.mrnRegex.ReplaceAllString(text, "[REDACTED-MRN]")
-	text = e.dateRegex.ReplaceAllString(text, "[REDACTED-DATE]")
-	text = e.ipRegex.ReplaceAllString(text, "[REDACTED-IP]")
-	text = e.urlRegex.ReplaceAllString(text, "[REDACTED-URL]")
-	text = e.accountRegex.ReplaceAllString(text, "[REDACTED-ACCOUNT]")
-	text = e.licenseRegex.ReplaceAllString(text, "[REDACTED-LICENSE]")
-	text = e.vinRegex.ReplaceAllString(text, "[REDACTED-VIN]")
-	text = e.zipRegex.ReplaceAllString(text, "[REDACTED-ZIP]")
+	// Redact in registry order (most specific first)
+	for _, d := range e.registry.Detectors() {
+		if g, ok := d.(Generalizer); ok && mode == RedactSafeHarbor {
+			text = g.Generalize(text)
+			continue
+		}
+		text = d.Redact(text)
+	}
 
 	return []byte(text)
 }
@@ -0,0 +1,124 @@
+package risk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Finding is a single located identifier within a scanned file
+type Finding struct {
+	Part       string  `json:"part,omitempty"` // Part of a multi-part file, e.g. "word/comments.xml"
+	Detector   string  `json:"detector"`       // Detector name, e.g. "SSN"
+	Identifier int     `json:"identifier"`     // HIPAA Safe Harbor identifier number (0 = non-HIPAA)
+	Category   string  `json:"category"`       // Identifier category, e.g. "Social Security Numbers"
+	Line       int     `json:"line"`           // 1-based line number, within Part if set
+	Column     int     `json:"column"`         // 1-based column (in characters)
+	Start      int     `json:"start"`          // Byte offset into the extracted text
+	End        int     `json:"end"`            // Byte offset just past the match
+	Confidence float64 `json:"confidence"`     // 0.0 - 1.0
+	ValueHash  string  `json:"valueHash"`      // Salted hash of the normalized value (never the value itself)
+}
+
+// Location is where the finding is, as "line:column", preceded by the part
+// of a multi-part file ("word/comments.xml 3:14")
+func (f Finding) Location() string {
+	if f.Part != "" {
+		return fmt.Sprintf("%s %d:%d", f.Part, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%d:%d", f.Line, f.Column)
+}
+
+// identifierCategories names the 18 HIPAA Safe Harbor identifiers (45 CFR 164.514(b)(2))
+var identifierCategories = map[int]string{
+	0:  "Payment Card Data (PCI-DSS)",
+	1:  "Names",
+	2:  "Geographic Subdivisions",
+	3:  "Dates",
+	4:  "Telephone Numbers",
+	5:  "Fax Numbers",
+	6:  "Email Addresses",
+	7:  "Social Security Numbers",
+	8:  "Medical Record Numbers",
+	9:  "Health Plan Beneficiary Numbers",
+	10: "Account Numbers",
+	11: "Certificate/License Numbers",
+	12: "Vehicle Identifiers",
+	13: "Device Identifiers",
+	14: "Web URLs",
+	15: "IP Addresses",
+	16: "Biometric Identifiers",
+	17: "Full-Face Photographs",
+	18: "Other Unique Identifying Numbers",
+}
+
+// IdentifierCategory returns the Safe Harbor category name for an identifier number
+func IdentifierCategory(identifier int) string {
+	if name, ok := identifierCategories[identifier]; ok {
+		return name
+	}
+	return fmt.Sprintf("Identifier #%d", identifier)
+}
+
+// hashValue returns a salted HMAC of a matched value so findings can be
+// compared and deduplicated without ever storing the PHI itself
+func hashValue(salt []byte, value string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// lineIndex maps byte offsets in a text to line and column numbers
+type lineIndex struct {
+	text   string
+	starts []int // byte offset at which each line begins
+}
+
+func newLineIndex(text string) *lineIndex {
+	idx := &lineIndex{text: text, starts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			idx.starts = append(idx.starts, i+1)
+		}
+	}
+	return idx
+}
+
+// position returns the 1-based line and column for a byte offset
+func (idx *lineIndex) position(offset int) (line, column int) {
+	line = sort.Search(len(idx.starts), func(i int) bool { return idx.starts[i] > offset })
+	return line, utf8.RuneCountInString(idx.text[idx.starts[line-1]:offset]) + 1
+}
+
+// summarizeFindings renders findings as the legacy per-line summary strings,
+// e.g. "Line 12: 2 SSN(s) found", prefixed with the part if there is one
+// ("word/comments.xml: Line 3: 1 Person Name(s) found")
+func summarizeFindings(findings []Finding) []string {
+	type key struct {
+		part     string
+		line     int
+		detector string
+	}
+	counts := make(map[key]int)
+	var order []key
+	for _, f := range findings {
+		k := key{f.Part, f.Line, f.Detector}
+		if _, seen := counts[k]; !seen {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+
+	summary := make([]string, 0, len(order))
+	for _, k := range order {
+		line := fmt.Sprintf("Line %d: %d %s(s) found", k.line, counts[k], k.detector)
+		if k.part != "" {
+			line = k.part + ": " + line
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
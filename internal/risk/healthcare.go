@@ -0,0 +1,51 @@
+package risk
+
+import "regexp"
+
+// Healthcare billing identifiers. Each pattern is checksum or structure
+// validated, since bare digit runs are everywhere in billing exports.
+
+const (
+	// mbiLetter is the MBI alphabet: A-Z minus S, L, O, I, B and Z
+	mbiLetter = `[AC-HJKMNP-RT-Y]`
+	mbiAlnum  = `[AC-HJKMNP-RT-Y0-9]`
+)
+
+// NewHICNDetector finds legacy Medicare Health Insurance Claim Numbers (#9):
+// an SSN-based 9 digits plus a beneficiary code such as A, B1 or C2
+func NewHICNDetector() Detector {
+	return NewRegexDetector(detectorHICN, 9, 15,
+		regexp.MustCompile(`\b\d{3}-?\d{2}-?\d{4}-?[A-HJ-MTW][0-9A-Z]?\b`), "[REDACTED-HICN]", ValidateHICN)
+}
+
+// NewMBIDetector finds 11-character Medicare Beneficiary Identifiers (#9), e.g. 1EG4-TE5-MK73
+func NewMBIDetector() Detector {
+	return NewRegexDetector(detectorMBI, 9, 15,
+		regexp.MustCompile(`\b[1-9]`+mbiLetter+mbiAlnum+`\d-?`+mbiLetter+mbiAlnum+`\d-?`+mbiLetter+mbiLetter+`\d{2}\b`), "[REDACTED-MBI]", ValidateMBI)
+}
+
+// NewDEADetector finds DEA registration numbers (#11): registrant type,
+// name initial and 7 digits ending in a check digit
+func NewDEADetector() Detector {
+	return NewRegexDetector("DEA Number", 11, 8,
+		regexp.MustCompile(`\b[ABCDEFGHJKLMPRSTUX][A-Z9]\d{7}\b`), "[REDACTED-DEA]", ValidateDEA)
+}
+
+// NewNPIDetector finds National Provider Identifiers (#18): 10 digits with a
+// Luhn check digit computed over "80840" + NPI. One in ten phone numbers
+// passes that check, so the digits must follow a label ("NPI:", "Provider
+// ID"); unlabelled NPIs are found by their column header in tables.
+func NewNPIDetector() Detector {
+	label := `(?:npi|(?:provider|prescriber|physician)[ \t]*(?:npi|id|#|no\.?|num(?:ber)?))`
+	return NewRegexDetector("NPI", 18, 5,
+		regexp.MustCompile(`(?i:\b`+label+`[ \t]*(?:#|no\.?|num(?:ber)?)?[ \t]*[:#]?[ \t]*)(?P<value>[12]\d{9})\b`), "[REDACTED-NPI]", ValidateNPI)
+}
+
+// NewMemberIDDetector finds health plan member/subscriber IDs (#9) that follow
+// a plan label ("Member ID:", "Policy #") or a common payer name ("Aetna ID")
+func NewMemberIDDetector() Detector {
+	label := `(?:member|subscriber|policy|group|insurance|insured|beneficiary|plan|medicaid|medicare)[ \t]*(?:id|#|no\.?|num(?:ber)?)`
+	payer := `(?:aetna|cigna|united[ \t]*health(?:care)?|uhc|humana|bcbs|blue[ \t]*cross|blue[ \t]*shield|anthem|kaiser|tricare|molina|centene|ambetter|wellcare|highmark|oscar)(?:[ \t]*(?:member[ \t]*)?(?:id|#))?`
+	return NewRegexDetector(detectorMemberID, 9, 12,
+		regexp.MustCompile(`(?i:\b(?:`+label+`|`+payer+`)[ \t]*[:#]?[ \t]*)(?P<value>[A-Z0-9][A-Z0-9\-]{5,19})\b`), "[REDACTED-MEMBER-ID]", RequireDigit)
+}
@@ -0,0 +1,78 @@
+package risk
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BreachNotificationThreshold is the number of affected individuals at which
+// the HHS Breach Notification Rule requires notifying HHS and the media
+// within 60 days (45 CFR 164.406, 164.408)
+const BreachNotificationThreshold = 500
+
+// individualDetectors are the detectors whose values each point to a single
+// person. Shared values (dates, ZIP codes, provider NPIs) are left out.
+var individualDetectors = map[string]bool{
+	detectorSSN:      true,
+	detectorHICN:     true,
+	detectorMBI:      true,
+	detectorMRN:      true,
+	detectorMemberID: true,
+	detectorName:     true,
+	detectorEmail:    true,
+}
+
+// individualSet holds distinct value hashes per detector. It lives in memory
+// only; the hashes are salted per process and never persisted.
+type individualSet map[string]map[string]struct{}
+
+func (s individualSet) add(detector, hash string) {
+	if s[detector] == nil {
+		s[detector] = make(map[string]struct{})
+	}
+	s[detector][hash] = struct{}{}
+}
+
+func (s individualSet) merge(other individualSet) {
+	for detector, hashes := range other {
+		for h := range hashes {
+			s.add(detector, h)
+		}
+	}
+}
+
+// estimate returns the largest distinct count of any one detector. One person
+// usually shows up under several (name, SSN, MRN), so summing would overcount.
+func (s individualSet) estimate() int {
+	best := 0
+	for _, hashes := range s {
+		if len(hashes) > best {
+			best = len(hashes)
+		}
+	}
+	return best
+}
+
+// normalizeValue folds formatting differences ("123-45-6789" vs "123456789",
+// "JANE DOE" vs "Jane Doe") so the same value hashes the same way
+func normalizeValue(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return sb.String()
+}
+
+// identityKey normalizes a matched value for hashing. Name word order is
+// ignored so "Doe, Jane" and "Jane Doe" count as one person.
+func identityKey(detector, value string) string {
+	if detector != detectorName {
+		return normalizeValue(value)
+	}
+	words := strings.FieldsFunc(strings.ToUpper(value), func(r rune) bool { return !unicode.IsLetter(r) })
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
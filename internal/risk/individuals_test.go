@@ -0,0 +1,15 @@
+package risk
+
+import "testing"
+
+func TestIndividualDetectorsAreRegistered(t *testing.T) {
+	registered := map[string]bool{}
+	for _, d := range DefaultRegistry().Detectors() {
+		registered[d.Name()] = true
+	}
+	for name := range individualDetectors {
+		if !registered[name] {
+			t.Errorf("individualDetectors names %q, which is not a built-in detector", name)
+		}
+	}
+}
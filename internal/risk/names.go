@@ -0,0 +1,334 @@
+package risk
+
+import (
+	_ "embed"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Offline name gazetteer (US Census frequency lists, bundled into the binary)
+var (
+	//go:embed data/first_names.txt
+	firstNamesData string
+	//go:embed data/surnames.txt
+	surnamesData string
+)
+
+// nameGazetteer maps upper-cased names to their frequency per 100k people
+type nameGazetteer struct {
+	first map[string]int
+	last  map[string]int
+}
+
+var (
+	gazetteerOnce sync.Once
+	gazetteer     *nameGazetteer
+)
+
+// loadGazetteer parses the embedded name lists once per process
+func loadGazetteer() *nameGazetteer {
+	gazetteerOnce.Do(func() {
+		gazetteer = &nameGazetteer{
+			first: parseNameList(firstNamesData),
+			last:  parseNameList(surnamesData),
+		}
+	})
+	return gazetteer
+}
+
+// parseNameList reads "NAME FREQUENCY" lines, skipping # comments
+func parseNameList(data string) map[string]int {
+	names := make(map[string]int)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		freq := 1
+		if len(fields) > 1 {
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				freq = n
+			}
+		}
+		names[strings.ToUpper(fields[0])] = freq
+	}
+	return names
+}
+
+// ambiguousNameWords are gazetteer entries that are also everyday English words,
+// so a bare "Mark Green" style pair is less certain without a context cue
+var ambiguousNameWords = map[string]bool{
+	"APRIL": true, "BELL": true, "BILL": true, "BLACK": true, "BROWN": true,
+	"BUSH": true, "CHASE": true, "CROSS": true, "DAWN": true, "DAY": true,
+	"FIELDS": true, "FOX": true, "FRANK": true, "GRACE": true, "GRAY": true,
+	"GREEN": true, "GUY": true, "HALL": true, "HILL": true, "HOPE": true,
+	"JOY": true, "JUNE": true, "KING": true, "LONG": true, "LOVE": true,
+	"MARK": true, "MAX": true, "MAY": true, "MILLS": true, "NEW": true,
+	"PARK": true, "PAGE": true, "PRICE": true, "RAY": true, "RICH": true,
+	"ROSE": true, "SHORT": true, "STONE": true, "STRONG": true, "WALL": true,
+	"WEST": true, "WHITE": true, "WISE": true, "WOOD": true, "WOODS": true,
+	"YOUNG": true, "SUMMERS": true, "WINTERS": true, "BANKS": true, "POWERS": true,
+}
+
+var (
+	// Capitalized word or bare initial ("J." / "J")
+	nameTokenRegex = regexp.MustCompile(`\b[A-Z][A-Za-z'\-]+\b|\b[A-Z]\b\.?`)
+
+	// Field labels that introduce a person's name: "Patient: Jane Doe", "Name: Doe, Jane"
+	nameCueRegex = regexp.MustCompile(`(?i:\b(?:patient(?:[ \t]+name)?|pt|name|full[ \t]+name|client|resident|member|guarantor|insured|subscriber|responsible[ \t]+party|emergency[ \t]+contact|parent|guardian|spouse)[ \t]*:[ \t]*)`)
+
+	// Honorifics that precede a name: "Mr. Smith", "Dr. Jane Doe"
+	nameTitleRegex = regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Mx|Dr|Prof)\.?[ \t]+`)
+)
+
+// nameStopWords are field labels that often follow a name on the same line
+// ("Patient: Doe, Jane SSN ...") and must never be taken as part of it
+var nameStopWords = map[string]bool{
+	"SSN": true, "DOB": true, "MRN": true, "NPI": true, "ID": true, "AGE": true,
+	"SEX": true, "DOS": true, "PHONE": true, "TEL": true, "FAX": true, "EMAIL": true,
+	"ACCT": true, "ACCOUNT": true, "MBI": true, "HICN": true, "DX": true, "RX": true,
+}
+
+// NameDetector finds person names (HIPAA identifier #1) using the bundled
+// gazetteer plus context cues such as "Patient:", "Mr." and "Name" columns
+// of delimited text. Parsed CSV/XLSX tables are flagged by their header in
+// columns.go instead.
+type NameDetector struct {
+	gaz *nameGazetteer
+}
+
+// NewNameDetector creates a name detector backed by the embedded gazetteer
+func NewNameDetector() Detector {
+	return &NameDetector{gaz: loadGazetteer()}
+}
+
+func (d *NameDetector) Name() string    { return detectorName }
+func (d *NameDetector) Identifier() int { return 1 }
+func (d *NameDetector) Weight() int     { return 8 }
+
+// nameToken is a capitalized word with its byte span
+type nameToken struct {
+	start, end int
+	word       string // upper-cased, trailing "." stripped
+}
+
+func (t nameToken) isInitial() bool { return len(t.word) == 1 }
+
+func (d *NameDetector) Find(text string) []Match {
+	return mergeOverlapping(text, append(d.findOutsideTables(text), d.findNameColumns(text)...))
+}
+
+// findOutsideTables finds names by cue and gazetteer only
+func (d *NameDetector) findOutsideTables(text string) []Match {
+	var tokens []nameToken
+	for _, loc := range nameTokenRegex.FindAllStringIndex(text, -1) {
+		tokens = append(tokens, nameToken{
+			start: loc[0],
+			end:   loc[1],
+			word:  strings.ToUpper(strings.TrimSuffix(text[loc[0]:loc[1]], ".")),
+		})
+	}
+
+	var matches []Match
+	matches = append(matches, d.findCued(text, tokens, nameCueRegex, 0.95)...)
+	matches = append(matches, d.findCued(text, tokens, nameTitleRegex, 0.9)...)
+	matches = append(matches, d.findPairs(text, tokens)...)
+	return mergeOverlapping(text, matches)
+}
+
+func (d *NameDetector) Redact(text string) string {
+	return replaceMatches(text, d.Find(text), func(Match) string { return "[REDACTED-NAME]" })
+}
+
+// findCued returns the name that directly follows each cue match. The first
+// token is trusted because of the cue; later tokens must be initials, known
+// names, or a single unknown surname right after a given name or initial.
+func (d *NameDetector) findCued(text string, tokens []nameToken, cue *regexp.Regexp, confidence float64) []Match {
+	var matches []Match
+	for _, loc := range cue.FindAllStringIndex(text, -1) {
+		i := sort.Search(len(tokens), func(i int) bool { return tokens[i].start >= loc[1] })
+		if i == len(tokens) || tokens[i].start != loc[1] || tokens[i].isInitial() {
+			continue
+		}
+
+		last := i
+		for j := i + 1; j < len(tokens) && j <= i+3; j++ {
+			if !nameGap(text[tokens[j-1].end:tokens[j].start]) {
+				break
+			}
+			if nameStopWords[tokens[j].word] {
+				break
+			}
+			prev := tokens[j-1]
+			_, prevIsFirst := d.gaz.first[prev.word]
+			if !tokens[j].isInitial() && !d.isKnownName(tokens[j].word) && !prevIsFirst && !prev.isInitial() {
+				break
+			}
+			last = j
+			if !tokens[j].isInitial() && !d.isKnownName(tokens[j].word) {
+				break // an unknown surname ends the name
+			}
+		}
+		// Drop a dangling trailing initial ("Dr. Smith J")
+		for last > i && tokens[last].isInitial() && !strings.HasSuffix(text[tokens[last].start:tokens[last].end], ".") {
+			last--
+		}
+
+		start, end := tokens[i].start, tokens[last].end
+		matches = append(matches, Match{Start: start, End: end, Value: text[start:end], Confidence: confidence})
+	}
+	return matches
+}
+
+// findPairs matches "First [M.] Last" and "Last, First" using the gazetteer alone
+func (d *NameDetector) findPairs(text string, tokens []nameToken) []Match {
+	var matches []Match
+	for i := 0; i+1 < len(tokens); i++ {
+		a := tokens[i]
+		if a.isInitial() || !nameGap(text[a.end:tokens[i+1].start]) {
+			continue
+		}
+
+		// First Last / First M. Last
+		if _, ok := d.gaz.first[a.word]; ok {
+			j := i + 1
+			if tokens[j].isInitial() && j+1 < len(tokens) && nameGap(text[tokens[j].end:tokens[j+1].start]) {
+				j++
+			}
+			if b := tokens[j]; !b.isInitial() {
+				if _, ok := d.gaz.last[b.word]; ok {
+					matches = append(matches, Match{
+						Start:      a.start,
+						End:        b.end,
+						Value:      text[a.start:b.end],
+						Confidence: d.pairConfidence(a.word, b.word),
+					})
+					i = j
+					continue
+				}
+			}
+		}
+
+		// Last, First
+		b := tokens[i+1]
+		if strings.TrimSpace(text[a.end:b.start]) == "," && !b.isInitial() {
+			_, isLast := d.gaz.last[a.word]
+			_, isFirst := d.gaz.first[b.word]
+			if isLast && isFirst {
+				matches = append(matches, Match{
+					Start:      a.start,
+					End:        b.end,
+					Value:      text[a.start:b.end],
+					Confidence: d.pairConfidence(b.word, a.word),
+				})
+				i++
+			}
+		}
+	}
+	return matches
+}
+
+// pairConfidence scores an uncued first/last pair: common names are more
+// likely to be people, dictionary words are less
+func (d *NameDetector) pairConfidence(first, last string) float64 {
+	confidence := 0.6
+	if d.gaz.first[first] >= 200 {
+		confidence += 0.15
+	}
+	if d.gaz.last[last] >= 100 {
+		confidence += 0.15
+	}
+	if ambiguousNameWords[first] || ambiguousNameWords[last] {
+		confidence -= 0.3
+	}
+	return confidence
+}
+
+func (d *NameDetector) isKnownName(word string) bool {
+	_, first := d.gaz.first[word]
+	_, last := d.gaz.last[word]
+	return first || last
+}
+
+// nameGap reports whether the text between two tokens keeps them in one name
+func nameGap(between string) bool {
+	between = strings.TrimLeft(between, ".")
+	return between != "" && strings.Trim(between, " \t,") == "" && strings.Count(between, ",") <= 1
+}
+
+// findNameColumns treats delimited text whose first line has a name header
+// ("Patient Name", "Last Name", ...) as a table and flags every non-empty
+// cell in those columns
+func (d *NameDetector) findNameColumns(text string) []Match {
+	headerEnd := strings.IndexByte(text, '\n')
+	if headerEnd < 0 {
+		return nil
+	}
+	header := strings.TrimSuffix(text[:headerEnd], "\r")
+
+	delim := ""
+	for _, candidate := range []string{"\t", ",", "|", ";"} {
+		if strings.Contains(header, candidate) {
+			delim = candidate
+			break
+		}
+	}
+	if delim == "" {
+		return nil
+	}
+
+	columns := make(map[int]bool)
+	for i, cell := range splitCells(header, delim) {
+		if sem, ok := columnHeaders[normalizeHeader(cell.text)]; ok && sem.detector == detectorName {
+			columns[i] = true
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	offset := headerEnd + 1
+	for _, line := range strings.Split(text[offset:], "\n") {
+		for i, cell := range splitCells(strings.TrimSuffix(line, "\r"), delim) {
+			if !columns[i] {
+				continue
+			}
+			value := strings.TrimSpace(cell.text)
+			if unquoted := strings.Trim(value, `"`); unquoted == "" || !strings.ContainsAny(unquoted, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz") {
+				continue
+			}
+			start := offset + cell.start + strings.Index(cell.text, value)
+			matches = append(matches, Match{Start: start, End: start + len(value), Value: value, Confidence: headerConfidence})
+		}
+		offset += len(line) + 1
+	}
+	return matches
+}
+
+// cell is one delimited field and its byte offset within the line
+type cell struct {
+	start int
+	text  string
+}
+
+// splitCells splits a delimited line, honoring double-quoted fields
+func splitCells(line, delim string) []cell {
+	var cells []cell
+	start := 0
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && strings.HasPrefix(line[i:], delim):
+			cells = append(cells, cell{start: start, text: line[start:i]})
+			start = i + len(delim)
+		}
+	}
+	return append(cells, cell{start: start, text: line[start:]})
+}
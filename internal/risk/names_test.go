@@ -0,0 +1,34 @@
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactContentNameColumn(t *testing.T) {
+	got := string(NewRiskEngine().RedactContent([]byte("Name,Diagnosis\nXochitl Zbigniewski,flu\n")))
+	if strings.Contains(got, "Xochitl") || !strings.Contains(got, "[REDACTED-NAME],flu") {
+		t.Errorf("RedactContent = %q, want the Name column redacted", got)
+	}
+}
+
+func TestNameColumnScoredOnceInCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patients.csv")
+	if err := os.WriteFile(path, []byte("Name,Diagnosis\nXochitl Zbigniewski,flu\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := NewRiskEngine().AnalyzeFileRisk(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range profile.Details {
+		if f.Detector == detectorName {
+			t.Errorf("text pass flagged a name in a parsed table (line %d); its column is scored from the header", f.Line)
+		}
+	}
+	if len(profile.Columns) != 1 || profile.Columns[0].Detector != detectorName {
+		t.Errorf("columns = %+v, want the Name column", profile.Columns)
+	}
+}
@@ -0,0 +1,138 @@
+package risk
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Registry holds the ordered set of detectors a RiskEngine runs.
+// Order matters for redaction: more specific detectors are registered first
+// so that broader patterns (ZIP, VIN) do not eat parts of SSNs or cards.
+type Registry struct {
+	mu        sync.RWMutex
+	detectors []Detector
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry returns a registry loaded with the built-in HIPAA detectors
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, d := range builtinDetectors() {
+		r.Register(d)
+	}
+	return r
+}
+
+// Register appends a detector. Detector names must be unique.
+func (r *Registry) Register(d Detector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.detectors {
+		if existing.Name() == d.Name() {
+			return fmt.Errorf("detector %q already registered", d.Name())
+		}
+	}
+	r.detectors = append(r.detectors, d)
+	return nil
+}
+
+// Unregister removes the detector with the given name, if present
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filtered := r.detectors[:0]
+	for _, d := range r.detectors {
+		if d.Name() != name {
+			filtered = append(filtered, d)
+		}
+	}
+	r.detectors = filtered
+}
+
+// Detectors returns a snapshot of the registered detectors in order
+func (r *Registry) Detectors() []Detector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Detector, len(r.detectors))
+	copy(out, r.detectors)
+	return out
+}
+
+// Names of the built-in detectors the engine refers to outside the registry
+const (
+	detectorSSN      = "SSN"
+	detectorHICN     = "Medicare HICN"
+	detectorMBI      = "Medicare MBI"
+	detectorMRN      = "Medical Record Number"
+	detectorMemberID = "Health Plan Member ID"
+	detectorName     = "Person Name"
+	detectorEmail    = "Email"
+)
+
+// builtinDetectors lists the stock HIPAA identifier patterns
+// (most specific first, this is also the redaction order)
+func builtinDetectors() []Detector {
+	return []Detector{
+		// #9: Legacy Medicare HICN (SSN + beneficiary code, so it must run before SSN)
+		NewHICNDetector(),
+
+		// #7: SSN - 123-45-6789 or 123456789
+		NewRegexDetector(detectorSSN, 7, 10, regexp.MustCompile(`\b\d{3}-?\d{2}-?\d{4}\b`), "[REDACTED-SSN]", ValidateSSN),
+
+		// PCI-DSS: Credit Cards
+		NewRegexDetector("Credit Card", 0, 10, regexp.MustCompile(`\b\d{4}[ \t-]?\d{4}[ \t-]?\d{4}[ \t-]?\d{4}\b`), "[REDACTED-CC]", ValidateCreditCard),
+
+		// #9, #11, #18: Medicare MBI, DEA and labelled NPI (before phone/MRN, which overlap their shapes)
+		NewMBIDetector(),
+		NewDEADetector(),
+		NewNPIDetector(),
+
+		// #9: Health plan member IDs next to a plan label or payer name
+		NewMemberIDDetector(),
+
+		// #4, #5: Phone/Fax - (555) 123-4567, 555-123-4567, 555.123.4567
+		NewRegexDetector("Phone/Fax Number", 4, 5, regexp.MustCompile(`\b(?:\+?1[ \t.-]?)?\(?\d{3}\)?[ \t.-]?\d{3}[ \t.-]?\d{4}\b`), "[REDACTED-PHONE]"),
+
+		// #6: Email addresses
+		NewRegexDetector(detectorEmail, 6, 5, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}\b`), "[REDACTED-EMAIL]"),
+
+		// #2: Street addresses, PO boxes, cities and counties
+		NewAddressDetector(),
+
+		// #1: Person names (gazetteer + context cues)
+		NewNameDetector(),
+
+		// #8: MRN - Medical Record Numbers (various formats)
+		NewRegexDetector(detectorMRN, 8, 15, regexp.MustCompile(`\b(?:MRN|M\.?R\.?N\.?)[: \t#]*[A-Z0-9]{6,12}\b|\b[A-Z]{2,3}\d{6,9}\b`), "[REDACTED-MRN]"),
+
+		// #3: Dates - numeric, ISO 8601 and written forms; ages over 89
+		NewDateDetector(),
+		NewAgeDetector(),
+
+		// #15: IP Addresses (IPv4)
+		NewRegexDetector("IP Address", 15, 3, regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[REDACTED-IP]"),
+
+		// #14: URLs
+		NewRegexDetector("URL", 14, 5, regexp.MustCompile(`\b(?:https?://|www\.)[-A-Za-z0-9+&@#/%?=~_|!:,.;]*[-A-Za-z0-9+&@#/%=~_|]`), "[REDACTED-URL]"),
+
+		// #10: Account Numbers (generic pattern)
+		NewRegexDetector("Account Number", 10, 10, regexp.MustCompile(`\b(?:Account|Acct|Patient)[ \t]*#?:?[ \t]*[A-Z0-9]{6,15}\b`), "[REDACTED-ACCOUNT]"),
+
+		// #11: License Numbers - DL, Driver License, etc.
+		NewRegexDetector("License/ID Number", 11, 8, regexp.MustCompile(`\b(?:DL|Driver'?s? License|License)[ \t]*#?:?[ \t]*[A-Z0-9]{6,15}\b`), "[REDACTED-LICENSE]"),
+
+		// #12: VIN (Vehicle Identification Number)
+		NewRegexDetector("Vehicle ID", 12, 8, regexp.MustCompile(`\b[A-HJ-NPR-Z0-9]{17}\b`), "[REDACTED-VIN]"),
+
+		// #2: ZIP codes (US 5 or 9 digit)
+		NewRegexDetector("ZIP Code", 2, 3, regexp.MustCompile(`\b\d{5}(?:-\d{4})?\b`), "[REDACTED-ZIP]"),
+	}
+}
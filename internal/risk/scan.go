@@ -0,0 +1,423 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hipaa-app/internal/content"
+	"hipaa-app/internal/pathfilter"
+)
+
+// ScanOptions tunes how AnalyzeDirectory spreads work across goroutines
+type ScanOptions struct {
+	Workers     int                   // Files analyzed concurrently (0 = number of CPUs)
+	FileTimeout time.Duration         // Per-file analysis limit (0 = no limit)
+	Cache       ResultCache           // Reuse results for unchanged files (nil = analyze everything)
+	Rules       pathfilter.Rules      // Include/exclude globs and limits applied to the walk
+	Checkpoint  Checkpoint            // Record finished files so an interrupted scan can resume (nil = off)
+	Archives    content.ArchiveLimits // Bounds on expanding archives and mail (zero fields = defaults)
+}
+
+// CheckpointEntry is the outcome of one file in an interrupted scan, with
+// the version of the file and of the rules it was analyzed under
+type CheckpointEntry struct {
+	Profile  RiskProfile    // ValueHashes are stripped before recording
+	Archive  *ArchiveResult // Set instead of Profile for archives and mail files
+	TimedOut bool
+	Size     int64  // Bytes
+	ModTime  int64  // Unix nanoseconds
+	Ruleset  string // RulesetVersion at the time of analysis
+}
+
+// Checkpoint persists per-file progress of one scan. Files finished by an
+// earlier attempt are taken from it instead of being analyzed again.
+// Implementations must be safe for concurrent use.
+type Checkpoint interface {
+	Completed(path string) (CheckpointEntry, bool)
+	Record(path string, entry CheckpointEntry) error
+}
+
+// DefaultScanOptions uses one worker per CPU and a two minute per-file limit
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{
+		Workers:     runtime.NumCPU(),
+		FileTimeout: 2 * time.Minute,
+	}
+}
+
+// SetScanOptions changes the worker count and per-file timeout for later scans
+func (e *RiskEngine) SetScanOptions(opts ScanOptions) {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.FileTimeout < 0 {
+		opts.FileTimeout = 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scanOpts = opts
+}
+
+// ScanOptions returns the options used by AnalyzeDirectory
+func (e *RiskEngine) ScanOptions() ScanOptions {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.scanOpts
+}
+
+// scanJob is one file handed from the walker to the workers; index is its
+// position in walk order so results can be merged deterministically
+type scanJob struct {
+	index int
+	path  string
+}
+
+type scanResult struct {
+	scanJob
+	profile  RiskProfile
+	timedOut bool
+	cached   bool
+	resumed  bool
+	format   content.Format // as detected before analysis
+	binary   bool           // unrecognized binary data with nothing to extract
+	err      error          // extraction failed or the format is unsupported
+	archive  *ArchiveResult // set for archives, whose files are reported individually
+}
+
+// UnscannedFile is a file the scan visited but could not read, so it must be
+// reviewed by hand
+type UnscannedFile struct {
+	Path   string `json:"path"`
+	Format string `json:"format"` // content.Format detected for the file
+	Reason string `json:"reason"`
+}
+
+// unscanned describes a file whose analysis failed
+func unscanned(path string, format content.Format, err error) UnscannedFile {
+	return UnscannedFile{Path: path, Format: string(format), Reason: err.Error()}
+}
+
+// countBinary tallies a file holding no format the scan can read (an
+// executable, video, font and the like) under its extension
+func countBinary(counts map[string]int, path string) map[string]int {
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		ext = "(none)"
+	}
+	counts[ext]++
+	return counts
+}
+
+// addCounts adds the per-extension counts of src to dst
+func addCounts(dst, src map[string]int) map[string]int {
+	for ext, n := range src {
+		if dst == nil {
+			dst = map[string]int{}
+		}
+		dst[ext] += n
+	}
+	return dst
+}
+
+// scanDirectory runs one walker feeding opts.Workers analyzers. Progress is
+// reported from a single goroutine as each file completes, and results are
+// merged in walk order regardless of which worker finished first.
+func (e *RiskEngine) scanDirectory(ctx context.Context, rootPath string, opts ScanOptions, progressCallback func(path string)) (AuditReport, error) {
+	report := AuditReport{
+		TopOffenders: []RiskProfile{},
+		individuals:  individualSet{},
+		model:        e.LiabilityModel(),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ruleset := ""
+	if opts.Cache != nil || opts.Checkpoint != nil {
+		ruleset = e.RulesetVersion()
+	}
+
+	jobs := make(chan scanJob, opts.Workers*2)
+	results := make(chan scanResult, opts.Workers*2)
+
+	// Walker
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		index := 0
+		walkErr <- pathfilter.New(rootPath, opts.Rules).Walk(func(path string, d fs.DirEntry) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			select {
+			case jobs <- scanJob{index: index, path: path}:
+				index++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	// Workers
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				size, modTime, statErr := statFile(job.path)
+				if statErr == nil {
+					if res, ok := resumeFromCheckpoint(job, opts.Checkpoint, size, modTime, ruleset); ok { // finished before an interruption
+						select {
+						case results <- res:
+							continue
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+				var res scanResult
+				if abandonedAnalyses.Load() >= maxAbandonedAnalyses {
+					// Stuck extractors hold on to their goroutines; rather
+					// than start more, leave the rest for a manual review
+					res = scanResult{scanJob: job, err: errTooManyStuck}
+				} else {
+					analyze := func() scanResult {
+						return e.analyzeJob(job, opts, ruleset)
+					}
+					res = analyzeWithTimeout(ctx, job, opts.FileTimeout, analyze)
+					// A timed-out file is left out so a resumed scan retries it
+					if ctx.Err() == nil && res.err == nil && !res.binary && !res.timedOut && statErr == nil {
+						recordCheckpoint(opts.Checkpoint, res, size, modTime, ruleset)
+					}
+				}
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Collector: the only goroutine touching report and the progress callback
+	var risky []scanResult
+	for res := range results {
+		if ctx.Err() != nil {
+			continue // drain so workers can exit
+		}
+		if progressCallback != nil {
+			progressCallback(res.path)
+		}
+
+		if res.binary {
+			report.SkippedBinaries = countBinary(report.SkippedBinaries, res.path)
+			continue
+		}
+		if res.err != nil {
+			report.Unscanned = append(report.Unscanned, unscanned(res.path, res.format, res.err))
+			continue
+		}
+		// An archive counts as one file, like in the walk that sized the
+		// progress total; the files inside are reported individually
+		report.TotalFiles++
+		if res.cached {
+			report.CachedFiles++
+		}
+		if res.resumed {
+			report.ResumedFiles++
+		}
+		if res.archive != nil {
+			report.ArchiveMembers += len(res.archive.Members)
+			report.Unscanned = append(report.Unscanned, res.archive.Unscanned...)
+			report.SkippedBinaries = addCounts(report.SkippedBinaries, res.archive.SkippedBinaries)
+			for _, profile := range res.archive.Members {
+				report.individuals.merge(profile.individuals)
+				if profile.RiskScore > 0 {
+					risky = append(risky, scanResult{scanJob: res.scanJob, profile: profile})
+				}
+			}
+			continue
+		}
+		if res.timedOut {
+			report.TimedOutFiles = append(report.TimedOutFiles, res.path)
+			continue
+		}
+		report.individuals.merge(res.profile.individuals)
+		if res.profile.RiskScore > 0 {
+			risky = append(risky, res)
+		}
+	}
+
+	sort.SliceStable(risky, func(i, j int) bool { return risky[i].index < risky[j].index })
+	for _, res := range risky {
+		profile := res.profile
+		report.TotalRiskScore += profile.RiskScore
+		if profile.UniqueIndividuals == 0 {
+			report.unnamed++
+		}
+		report.TopOffenders = append(report.TopOffenders, profile)
+		if profile.RiskLabel == "CRITICAL" {
+			report.CriticalCount++
+		}
+	}
+	sort.Strings(report.TimedOutFiles)
+	sortUnscanned(report.Unscanned)
+
+	report.finalize()
+
+	err := <-walkErr
+	if ctxErr := ctx.Err(); ctxErr != nil && err == nil {
+		err = ctxErr
+	}
+	return report, err
+}
+
+// resumeFromCheckpoint returns the result an earlier attempt recorded for
+// job, if the file and the rules are unchanged since. Files that timed out
+// and files that identify people are analyzed again: their value hashes cannot be persisted, and
+// without them individuals could not be de-duplicated against the rest of
+// the scan.
+func resumeFromCheckpoint(job scanJob, cp Checkpoint, size, modTime int64, ruleset string) (scanResult, bool) {
+	if cp == nil {
+		return scanResult{}, false
+	}
+	entry, ok := cp.Completed(job.path)
+	if !ok || entry.TimedOut || entry.Profile.UniqueIndividuals > 0 || entry.Archive != nil && entry.Archive.identifiesPeople() {
+		return scanResult{}, false
+	}
+	if entry.Size != size || entry.ModTime != modTime || entry.Ruleset != ruleset {
+		return scanResult{}, false // Edited since, or analyzed under other rules
+	}
+	return scanResult{scanJob: job, profile: entry.Profile, archive: entry.Archive, timedOut: entry.TimedOut, resumed: true}, true
+}
+
+// recordCheckpoint marks a finished file. size and modTime are taken before
+// the analysis, so an edit made while it ran is seen on resume.
+func recordCheckpoint(cp Checkpoint, res scanResult, size, modTime int64, ruleset string) {
+	if cp == nil || res.resumed {
+		return
+	}
+	entry := CheckpointEntry{
+		Profile:  withoutValueHashes(res.profile),
+		TimedOut: res.timedOut,
+		Size:     size,
+		ModTime:  modTime,
+		Ruleset:  ruleset,
+	}
+	if res.archive != nil {
+		stored := res.archive.withoutValueHashes()
+		entry.Archive = &stored
+	}
+	if err := cp.Record(res.path, entry); err != nil {
+		fmt.Printf("[Scan] Failed to checkpoint %s: %v\n", res.path, err)
+	}
+}
+
+// analyzeJob analyzes one walked file: archives are expanded in memory,
+// binaries of no readable format are only counted, and everything else,
+// archives included, goes through the cache when one is configured
+func (e *RiskEngine) analyzeJob(job scanJob, opts ScanOptions, ruleset string) scanResult {
+	res := scanResult{scanJob: job}
+	format, err := content.DetectFormat(job.path)
+	res.format = format
+	if err == nil && format == content.FormatUnknown {
+		res.binary = true
+		return res
+	}
+	if err == nil && format.IsArchive() {
+		var archive ArchiveResult
+		if opts.Cache == nil {
+			archive, err = e.AnalyzeArchive(job.path, opts.Archives)
+		} else {
+			archive, res.cached, err = e.analyzeArchiveCached(job.path, opts.Archives, opts.Cache, ruleset)
+		}
+		if err != nil {
+			res.err = err
+		} else {
+			res.archive = &archive
+		}
+		return res
+	}
+	if opts.Cache == nil {
+		res.profile, res.err = e.AnalyzeFileRisk(job.path)
+		return res
+	}
+	res.profile, res.cached, res.err = e.analyzeCached(job.path, opts.Cache, ruleset)
+	return res
+}
+
+// maxAbandonedAnalyses caps the timed-out analyses still running in the
+// background, across all scans. Once it is reached, further files are
+// reported as not scanned instead of being analyzed.
+const maxAbandonedAnalyses = 8
+
+var (
+	abandonedAnalyses atomic.Int32
+	errTooManyStuck   = fmt.Errorf("not analyzed: %d earlier files are stuck in analysis", maxAbandonedAnalyses)
+)
+
+// analyzeWithTimeout runs analyze, giving up after timeout. Extractors cannot
+// be interrupted, so an abandoned analysis finishes in the background, counted
+// in abandonedAnalyses, and its result is discarded in favour of one marked as
+// timed out.
+func analyzeWithTimeout(ctx context.Context, job scanJob, timeout time.Duration, analyze func() scanResult) scanResult {
+	if timeout <= 0 {
+		return analyze()
+	}
+
+	// state moves from running to finished or abandoned, whichever comes first
+	const (
+		running int32 = iota
+		finished
+		abandoned
+	)
+	var state atomic.Int32
+	done := make(chan scanResult, 1)
+	go func() {
+		res := analyze()
+		if !state.CompareAndSwap(running, finished) {
+			abandonedAnalyses.Add(-1)
+		}
+		done <- res
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var res scanResult
+	select {
+	case res = <-done:
+		return res
+	case <-timer.C:
+		fmt.Printf("[Scan] Timed out after %s: %s\n", timeout, job.path)
+		res = scanResult{scanJob: job, profile: RiskProfile{FilePath: job.path}, timedOut: true}
+	case <-ctx.Done():
+		res = scanResult{scanJob: job, profile: RiskProfile{FilePath: job.path}}
+	}
+	abandonedAnalyses.Add(1)
+	if !state.CompareAndSwap(running, abandoned) {
+		abandonedAnalyses.Add(-1) // finished in the meantime
+	}
+	return res
+}
+
+func sortUnscanned(files []UnscannedFile) {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+}
@@ -0,0 +1,181 @@
+package risk
+
+import "strings"
+
+// Validator checks a raw match and returns a confidence multiplier.
+// 1.0 keeps the hit as-is, values in (0, 1) down-weight it, and 0 vetoes it.
+type Validator func(value string) float64
+
+// applyValidators multiplies together the verdicts of every validator
+func applyValidators(validators []Validator, value string) float64 {
+	confidence := 1.0
+	for _, v := range validators {
+		confidence *= v(value)
+		if confidence <= 0 {
+			return 0
+		}
+	}
+	return confidence
+}
+
+// digitsOnly strips everything but ASCII digits
+func digitsOnly(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// knownInvalidSSNs are numbers published in advertising and never valid for a person
+var knownInvalidSSNs = map[string]bool{
+	"078051120": true, // Woolworth wallet card
+	"219099999": true, // Social Security Board pamphlet
+}
+
+// ValidateSSN applies the SSA assignment rules: no 000, 666 or 9xx area,
+// no 00 group and no 0000 serial. Mixed separators (e.g. ZIP+4 "12345-6789")
+// are vetoed, and bare 9-digit runs are down-weighted since order numbers
+// and timestamps look the same.
+func ValidateSSN(value string) float64 {
+	digits := digitsOnly(value)
+	if len(digits) != 9 {
+		return 0
+	}
+
+	dashed := strings.Count(value, "-")
+	if dashed != 0 && !(dashed == 2 && len(value) == 11 && value[3] == '-' && value[6] == '-') {
+		return 0
+	}
+
+	area, group, serial := digits[0:3], digits[3:5], digits[5:9]
+	if area == "000" || area == "666" || area[0] == '9' {
+		return 0
+	}
+	if group == "00" || serial == "0000" {
+		return 0
+	}
+	if knownInvalidSSNs[digits] {
+		return 0
+	}
+
+	if dashed == 0 {
+		return 0.6
+	}
+	return 1.0
+}
+
+// luhnValid reports whether a digit string passes the Luhn (mod 10) checksum
+func luhnValid(digits string) bool {
+	if len(digits) == 0 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ValidateCreditCard vetoes numbers that fail the Luhn checksum and
+// down-weights numbers outside the major card network prefixes (3-6)
+func ValidateCreditCard(value string) float64 {
+	digits := digitsOnly(value)
+	if len(digits) < 13 || len(digits) > 19 || !luhnValid(digits) {
+		return 0
+	}
+	if strings.Trim(digits, digits[:1]) == "" {
+		return 0 // all one digit, e.g. 0000 0000 0000 0000
+	}
+	if digits[0] < '3' || digits[0] > '6' {
+		return 0.5
+	}
+	return 1.0
+}
+
+// ValidateNPI checks the National Provider Identifier check digit, which is
+// a Luhn checksum computed over the number prefixed with "80840"
+func ValidateNPI(value string) float64 {
+	digits := digitsOnly(value)
+	if len(digits) != 10 || (digits[0] != '1' && digits[0] != '2') {
+		return 0
+	}
+	if !luhnValid("80840" + digits) {
+		return 0
+	}
+	return 1.0
+}
+
+// ValidateDEA checks a DEA registration number: a registrant-type letter, the
+// registrant's last-name initial (or 9) and seven digits whose last digit is
+// (d1+d3+d5 + 2*(d2+d4+d6)) mod 10
+func ValidateDEA(value string) float64 {
+	value = strings.ToUpper(value)
+	if len(value) != 9 || !strings.ContainsRune("ABCDEFGHJKLMPRSTUX", rune(value[0])) {
+		return 0
+	}
+	digits := value[2:]
+	if digitsOnly(digits) != digits {
+		return 0
+	}
+	d := func(i int) int { return int(digits[i] - '0') }
+	sum := d(0) + d(2) + d(4) + 2*(d(1)+d(3)+d(5))
+	if sum%10 != d(6) {
+		return 0
+	}
+	return 1.0
+}
+
+// ValidateMBI checks Medicare Beneficiary Identifier separators: either none
+// or the "1EG4-TE5-MK73" display form. The character classes are enforced by
+// the detector pattern itself.
+func ValidateMBI(value string) float64 {
+	switch strings.Count(value, "-") {
+	case 0:
+		return 1.0
+	case 2:
+		if len(value) == 13 && value[4] == '-' && value[8] == '-' {
+			return 1.0
+		}
+	}
+	return 0
+}
+
+// ValidateHICN checks that the 9-digit base of a legacy Health Insurance
+// Claim Number follows the SSN assignment rules it was derived from
+func ValidateHICN(value string) float64 {
+	base := strings.TrimRight(digitsPrefix(value), "-")
+	if ValidateSSN(base) == 0 {
+		return 0
+	}
+	return 1.0
+}
+
+// digitsPrefix returns the leading run of digits and dashes
+func digitsPrefix(s string) string {
+	for i, r := range s {
+		if (r < '0' || r > '9') && r != '-' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// RequireDigit vetoes label-anchored IDs that are plain words ("Member ID: PENDING")
+func RequireDigit(value string) float64 {
+	if digitsOnly(value) == "" {
+		return 0
+	}
+	return 1.0
+}
@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hipaa-app/internal/storage"
+)
+
+// Schedule is a calendar-aware recurrence: every N hours, days, weeks or
+// months at a wall-clock time of day in an IANA timezone
+type Schedule struct {
+	Every    int
+	Unit     string // "hours", "days", "weeks", "months"
+	Hour     int
+	Minute   int
+	Location *time.Location
+	Anchor   time.Time // first slot of the series; later slots count from here
+}
+
+// ScheduleFromConfig builds a schedule from the stored config, falling back
+// to the deprecated IntervalHours when no IntervalValue is set. The series is
+// anchored at the first TimeOfDay on or after now; the scheduler persists
+// that anchor so later restarts continue the same series.
+func ScheduleFromConfig(config *storage.ScheduleConfig, now time.Time) (Schedule, error) {
+	sc := Schedule{
+		Every: config.IntervalValue,
+		Unit:  strings.ToLower(strings.TrimSpace(config.IntervalUnit)),
+	}
+	if sc.Every <= 0 {
+		sc.Every, sc.Unit = config.IntervalHours, "hours"
+	}
+	if sc.Every <= 0 {
+		return Schedule{}, fmt.Errorf("no scan interval configured")
+	}
+	switch sc.Unit {
+	case "hour", "hours":
+		sc.Unit = "hours"
+	case "day", "days":
+		sc.Unit = "days"
+	case "week", "weeks":
+		sc.Unit = "weeks"
+	case "month", "months":
+		sc.Unit = "months"
+	default:
+		return Schedule{}, fmt.Errorf("unknown interval unit %q", config.IntervalUnit)
+	}
+
+	sc.Location = time.Local
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+		}
+		sc.Location = loc
+	}
+
+	local := now.In(sc.Location)
+	sc.Hour, sc.Minute = local.Hour(), local.Minute()
+	if config.TimeOfDay != "" {
+		t, err := time.Parse("15:04", config.TimeOfDay)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid time of day %q (want HH:MM)", config.TimeOfDay)
+		}
+		sc.Hour, sc.Minute = t.Hour(), t.Minute()
+	}
+
+	sc.Anchor = sc.at(local.Year(), local.Month(), local.Day())
+	if sc.Anchor.Before(now) && sc.Unit != "hours" {
+		sc.Anchor = sc.at(local.Year(), local.Month(), local.Day()+1)
+	}
+	return sc, nil
+}
+
+// Next returns the first slot strictly after t
+func (sc Schedule) Next(t time.Time) time.Time {
+	// Hourly series extend both ways from the anchor; calendar series start at it
+	if sc.Unit != "hours" && sc.Anchor.After(t) {
+		return sc.Anchor
+	}
+
+	switch sc.Unit {
+	case "hours":
+		step := time.Duration(sc.Every) * time.Hour
+		k := t.Sub(sc.Anchor) / step
+		next := sc.Anchor.Add(k * step)
+		for !next.After(t) {
+			next = next.Add(step)
+		}
+		for next.Add(-step).After(t) {
+			next = next.Add(-step)
+		}
+		return next
+
+	case "days", "weeks":
+		days := sc.Every
+		if sc.Unit == "weeks" {
+			days *= 7
+		}
+		a := sc.Anchor.In(sc.Location)
+		// Calendar days between the anchor and t, ignoring DST-shortened days
+		elapsed := int(civilDays(t.In(sc.Location)) - civilDays(a))
+		k := elapsed / days
+		if k < 0 {
+			k = 0
+		}
+		for {
+			next := sc.at(a.Year(), a.Month(), a.Day()+k*days)
+			if next.After(t) {
+				return next
+			}
+			k++
+		}
+
+	default: // months
+		a := sc.Anchor.In(sc.Location)
+		local := t.In(sc.Location)
+		elapsed := (local.Year()-a.Year())*12 + int(local.Month()-a.Month())
+		k := elapsed / sc.Every
+		if k < 0 {
+			k = 0
+		}
+		for {
+			next := sc.monthSlot(a, k*sc.Every)
+			if next.After(t) {
+				return next
+			}
+			k++
+		}
+	}
+}
+
+// monthSlot is the anchor moved forward n months, clamping the day to the
+// target month's length (Jan 31 -> Feb 28/29 -> Mar 31)
+func (sc Schedule) monthSlot(anchor time.Time, n int) time.Time {
+	first := time.Date(anchor.Year(), anchor.Month()+time.Month(n), 1, 0, 0, 0, 0, sc.Location)
+	day := anchor.Day()
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		day = last
+	}
+	return sc.at(first.Year(), first.Month(), day)
+}
+
+// at returns the schedule's time of day on a calendar date. A time inside a
+// spring-forward gap runs at the first instant after it (02:30 -> 03:30);
+// an ambiguous fall-back time runs at its first occurrence.
+func (sc Schedule) at(year int, month time.Month, day int) time.Time {
+	t := time.Date(year, month, day, sc.Hour, sc.Minute, 0, 0, sc.Location)
+	if t.Hour() != sc.Hour || t.Minute() != sc.Minute {
+		_, before := t.Zone()
+		_, after := t.Add(3 * time.Hour).Zone()
+		if shifted := t.Add(time.Duration(after-before) * time.Second); shifted.After(t) {
+			t = shifted
+		}
+	}
+	return t
+}
+
+// civilDays counts calendar days since the epoch for a wall-clock date
+func civilDays(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
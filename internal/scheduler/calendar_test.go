@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"hipaa-app/internal/storage"
+)
+
+// newYork has a spring-forward gap on 2026-03-08 (02:00 -> 03:00) and a
+// fall-back repeat on 2026-11-01 (01:00-02:00 twice)
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestScheduleNext(t *testing.T) {
+	ny := newYork(t)
+	at := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, ny)
+	}
+	// Second 01:30 on fall-back day, in EST
+	secondOneThirty := at(2026, time.November, 1, 1, 30).Add(time.Hour)
+
+	daily := func(h, min int, anchor time.Time) Schedule {
+		return Schedule{Every: 1, Unit: "days", Hour: h, Minute: min, Location: ny, Anchor: anchor}
+	}
+	monthly := func(anchor time.Time) Schedule {
+		return Schedule{Every: 1, Unit: "months", Hour: anchor.Hour(), Minute: anchor.Minute(), Location: ny, Anchor: anchor}
+	}
+
+	tests := []struct {
+		name  string
+		sc    Schedule
+		after time.Time
+		want  time.Time
+	}{
+		{"daily before anchor", daily(9, 0, at(2026, time.March, 1, 9, 0)), at(2026, time.February, 1, 0, 0), at(2026, time.March, 1, 9, 0)},
+		{"daily", daily(9, 0, at(2026, time.March, 1, 9, 0)), at(2026, time.March, 3, 9, 0), at(2026, time.March, 4, 9, 0)},
+		{"spring-forward gap runs after it", daily(2, 30, at(2026, time.March, 1, 2, 30)), at(2026, time.March, 7, 3, 0), at(2026, time.March, 8, 3, 30)},
+		{"day after the gap", daily(2, 30, at(2026, time.March, 1, 2, 30)), at(2026, time.March, 8, 3, 30), at(2026, time.March, 9, 2, 30)},
+		{"fall-back runs at first occurrence", daily(1, 30, at(2026, time.October, 1, 1, 30)), at(2026, time.October, 31, 2, 0), at(2026, time.November, 1, 1, 30)},
+		{"fall-back runs once", daily(1, 30, at(2026, time.October, 1, 1, 30)), at(2026, time.November, 1, 1, 30), at(2026, time.November, 2, 1, 30)},
+		{"fall-back from the repeat", daily(1, 30, at(2026, time.October, 1, 1, 30)), secondOneThirty, at(2026, time.November, 2, 1, 30)},
+		{"weekly", Schedule{Every: 2, Unit: "weeks", Hour: 8, Location: ny, Anchor: at(2026, time.March, 2, 8, 0)}, at(2026, time.March, 2, 8, 0), at(2026, time.March, 16, 8, 0)},
+		{"month end clamps to February", monthly(at(2026, time.January, 31, 9, 0)), at(2026, time.January, 31, 9, 0), at(2026, time.February, 28, 9, 0)},
+		{"month end returns to the 31st", monthly(at(2026, time.January, 31, 9, 0)), at(2026, time.February, 28, 9, 0), at(2026, time.March, 31, 9, 0)},
+		{"month end clamps to 30 days", monthly(at(2026, time.January, 31, 9, 0)), at(2026, time.March, 31, 9, 0), at(2026, time.April, 30, 9, 0)},
+		{"leap February", monthly(at(2028, time.January, 31, 9, 0)), at(2028, time.January, 31, 9, 0), at(2028, time.February, 29, 9, 0)},
+		{"quarterly", Schedule{Every: 3, Unit: "months", Hour: 9, Location: ny, Anchor: at(2026, time.January, 15, 9, 0)}, at(2026, time.February, 1, 0, 0), at(2026, time.April, 15, 9, 0)},
+		{"hourly keeps elapsed time across the gap", Schedule{Every: 6, Unit: "hours", Location: ny, Anchor: at(2026, time.March, 7, 12, 0)}, at(2026, time.March, 8, 0, 0), at(2026, time.March, 8, 0, 0).Add(6 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sc.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleFromConfig(t *testing.T) {
+	ny := newYork(t)
+	now := time.Date(2026, time.March, 5, 10, 0, 0, 0, ny)
+
+	sc, err := ScheduleFromConfig(&storage.ScheduleConfig{IntervalValue: 1, IntervalUnit: "Day", TimeOfDay: "09:00", Timezone: "America/New_York"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 09:00 has passed today, so the series starts tomorrow
+	if want := time.Date(2026, time.March, 6, 9, 0, 0, 0, ny); !sc.Anchor.Equal(want) || sc.Unit != "days" {
+		t.Errorf("anchor %s unit %q, want %s days", sc.Anchor, sc.Unit, want)
+	}
+
+	sc, err = ScheduleFromConfig(&storage.ScheduleConfig{IntervalHours: 12, Timezone: "America/New_York"}, now)
+	if err != nil || sc.Every != 12 || sc.Unit != "hours" {
+		t.Errorf("IntervalHours fallback = %+v, %v; want every 12 hours", sc, err)
+	}
+
+	for _, config := range []storage.ScheduleConfig{
+		{},
+		{IntervalValue: 1, IntervalUnit: "fortnights"},
+		{IntervalValue: 1, IntervalUnit: "days", Timezone: "Mars/Olympus"},
+		{IntervalValue: 1, IntervalUnit: "days", TimeOfDay: "9am"},
+	} {
+		if _, err := ScheduleFromConfig(&config, now); err == nil {
+			t.Errorf("ScheduleFromConfig(%+v) succeeded, want an error", config)
+		}
+	}
+}
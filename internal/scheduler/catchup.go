@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"hipaa-app/internal/storage"
+)
+
+const (
+	// missedGrace is how late a run may start and still count as on time
+	missedGrace = 5 * time.Minute
+	// maxMissedSlots bounds how many missed runs are counted for one job
+	maxMissedSlots = 10000
+	// wakeCheckInterval is how often the wall clock is compared with the
+	// monotonic clock; timers do not advance while the machine sleeps
+	wakeCheckInterval = time.Minute
+)
+
+// missedSlots lists the runs of j that were due at or before now, starting
+// with j.next
+func missedSlots(j *job, now time.Time) []time.Time {
+	var slots []time.Time
+	for t := j.next; !t.IsZero() && !t.After(now) && len(slots) < maxMissedSlots; t = j.trigger.Next(t) {
+		slots = append(slots, t)
+	}
+	return slots
+}
+
+// runDue runs every job that is due. A job that is on time simply runs; one
+// whose runs were missed (app closed, machine asleep) follows its catch-up
+// policy. At most one scan runs either way: scans back to back would find
+// the same files. Under "all" its audit entry covers every missed run,
+// otherwise the runs it skips are recorded as a "MISSED" audit entry.
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	for _, j := range s.dueJobs(now) {
+		if ctx.Err() != nil || s.stopping() {
+			return
+		}
+		slots := missedSlots(j, now)
+		run, covered, skipped := true, []time.Time(nil), []time.Time(nil)
+		if len(slots) > 1 || now.Sub(slots[0]) > missedGrace {
+			switch j.catchUp {
+			case storage.CatchUpSkip:
+				run, skipped = false, slots
+			case storage.CatchUpAll:
+				covered = slots
+			default:
+				skipped = slots[:len(slots)-1]
+			}
+			fmt.Printf("[Scheduler] Job %q missed %d run(s) since %s, catch-up policy %q\n",
+				j.name, len(slots), slots[0].Format(time.RFC1123), catchUpPolicy(j))
+		}
+		if len(skipped) > 0 {
+			s.recordMissed(j, skipped)
+		}
+
+		if run && ctx.Err() == nil && !s.stopping() {
+			s.runJob(ctx, j, covered)
+		}
+		// Count from now so a long scan never queues back-to-back runs
+		s.reschedule(j, time.Now())
+	}
+}
+
+// runJob executes a job and persists when it ran. covered lists the missed
+// runs a catch-up scan stands in for, if any.
+func (s *Scheduler) runJob(ctx context.Context, j *job, covered []time.Time) {
+	started := time.Now()
+	s.mu.Lock()
+	j.lastRun = started
+	for _, scheduled := range s.jobs { // a manual run loads its own copy
+		if scheduled.id == j.id {
+			scheduled.lastRun = started
+		}
+	}
+	s.mu.Unlock()
+	if err := s.store.SetJobLastRun(j.id, started); err != nil {
+		fmt.Printf("[Scheduler] Error saving state of job %q: %v\n", j.name, err)
+	}
+	s.executeScan(ctx, j, covered)
+}
+
+// recordMissed writes one "MISSED" audit entry covering the skipped runs so
+// gaps in the audit trail are explained rather than silent
+func (s *Scheduler) recordMissed(j *job, skipped []time.Time) {
+	first, last := skipped[0], skipped[len(skipped)-1]
+	note := fmt.Sprintf("Scheduled run at %s did not take place (app closed or computer asleep); catch-up policy %q",
+		first.Format(time.RFC3339), catchUpPolicy(j))
+	if len(skipped) > 1 {
+		note = fmt.Sprintf("%d scheduled runs between %s and %s did not take place (app closed or computer asleep); catch-up policy %q",
+			len(skipped), first.Format(time.RFC3339), last.Format(time.RFC3339), catchUpPolicy(j))
+	}
+
+	hostname, _ := os.Hostname()
+	entry := storage.AuditEntry{
+		Timestamp: first.Format(time.RFC3339),
+		User:      hostname,
+		Status:    "MISSED",
+		Job:       j.name,
+		Note:      note,
+	}
+	if err := s.store.AddAuditEntry(entry); err != nil {
+		fmt.Printf("[Scheduler] Error recording missed run: %v\n", err)
+	}
+	s.notify("scan:scheduled:missed", map[string]interface{}{
+		"job":     j.name,
+		"missed":  len(skipped),
+		"firstAt": first.Format(time.RFC3339),
+		"lastAt":  last.Format(time.RFC3339),
+	})
+}
+
+// stopping reports whether Stop has been called
+func (s *Scheduler) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func catchUpPolicy(j *job) string {
+	if j.catchUp == "" {
+		return storage.CatchUpOnce
+	}
+	return j.catchUp
+}
+
+// woke reports whether more wall-clock time passed since the last check than
+// the check interval explains, which happens when the machine was suspended
+func woke(lastCheck, now time.Time) bool {
+	// Round(0) strips the monotonic reading, which stops during sleep
+	return now.Round(0).Sub(lastCheck.Round(0)) > 2*wakeCheckInterval
+}
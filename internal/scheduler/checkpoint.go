@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"hipaa-app/internal/risk"
+	"hipaa-app/internal/storage"
+)
+
+// liveRuns holds the scan runs in progress in this process. A scheduler
+// replaced while its scan is still winding down leaves that run "running",
+// and its successor must not report it as interrupted.
+var liveRuns = struct {
+	sync.Mutex
+	ids map[int64]bool
+}{ids: map[int64]bool{}}
+
+// runCheckpoint adapts a stored scan run to risk.Checkpoint. done holds the
+// files finished before the run was interrupted and is read-only once loaded.
+type runCheckpoint struct {
+	store *storage.Store
+	runID int64
+	done  map[string]storage.ScanRunFile
+}
+
+func (c *runCheckpoint) Completed(path string) (risk.CheckpointEntry, bool) {
+	f, ok := c.done[path]
+	if !ok {
+		return risk.CheckpointEntry{}, false
+	}
+	entry := risk.CheckpointEntry{
+		Profile:  risk.RiskProfile{FilePath: path},
+		TimedOut: f.TimedOut,
+		Size:     f.Size,
+		ModTime:  f.ModTime,
+		Ruleset:  f.Ruleset,
+	}
+	if len(f.Result) > 0 {
+		if err := json.Unmarshal(f.Result, &entry.Profile); err != nil {
+			return risk.CheckpointEntry{}, false
+		}
+	}
+	if len(f.Archive) > 0 {
+		entry.Archive = &risk.ArchiveResult{}
+		if err := json.Unmarshal(f.Archive, entry.Archive); err != nil {
+			return risk.CheckpointEntry{}, false
+		}
+	}
+	return entry, true
+}
+
+func (c *runCheckpoint) Record(path string, entry risk.CheckpointEntry) error {
+	f := storage.ScanRunFile{
+		Path:      path,
+		RiskScore: entry.Profile.RiskScore,
+		TimedOut:  entry.TimedOut,
+		Size:      entry.Size,
+		ModTime:   entry.ModTime,
+		Ruleset:   entry.Ruleset,
+	}
+	// Clean files only need to be marked done
+	if entry.Profile.RiskScore > 0 || entry.Profile.UniqueIndividuals > 0 {
+		data, err := json.Marshal(entry.Profile)
+		if err != nil {
+			return err
+		}
+		f.Result = data
+	}
+	if entry.Archive != nil {
+		for _, m := range entry.Archive.Members {
+			f.RiskScore += m.RiskScore
+		}
+		data, err := json.Marshal(entry.Archive)
+		if err != nil {
+			return err
+		}
+		f.Archive = data
+	}
+	return c.store.RecordScanRunFile(c.runID, f)
+}
+
+// beginRun resumes the job's interrupted run when it covers the same paths,
+// or starts a new one. A nil checkpoint means the scan runs without one.
+func (s *Scheduler) beginRun(j *job, total int) *runCheckpoint {
+	cp := &runCheckpoint{store: s.store, done: map[string]storage.ScanRunFile{}}
+
+	run, found, err := s.store.LatestInterruptedRun(j.id)
+	if err != nil {
+		fmt.Printf("[Scheduler] Error loading interrupted run of job %q: %v\n", j.name, err)
+	}
+	if found && !samePaths(run.Paths, j.paths) {
+		fmt.Printf("[Scheduler] Scan paths of job %q changed, discarding interrupted run %d\n", j.name, run.ID)
+		s.store.DeleteScanRun(run.ID)
+		found = false
+	}
+
+	if found {
+		files, err := s.store.ScanRunFiles(run.ID)
+		if err == nil {
+			err = s.store.ResumeScanRun(run.ID, total)
+		}
+		if err == nil {
+			for _, f := range files {
+				cp.done[f.Path] = f
+			}
+			cp.runID = run.ID
+			markLive(cp.runID, true)
+			fmt.Printf("[Scheduler] Resuming run %d of job %q: %d file(s) already done\n", run.ID, j.name, len(files))
+			s.notify("log:info", fmt.Sprintf("Resuming %s scan: %d of %d files already done", j.name, len(files), total))
+			return cp
+		}
+		fmt.Printf("[Scheduler] Cannot resume run %d: %v\n", run.ID, err)
+	}
+
+	cp.runID, err = s.store.StartScanRun(j.id, j.name, j.paths, total)
+	if err != nil {
+		fmt.Printf("[Scheduler] Checkpointing disabled for this scan: %v\n", err)
+		return nil
+	}
+	markLive(cp.runID, true)
+	return cp
+}
+
+// endRun releases a run begun by beginRun once its scan has returned
+func (s *Scheduler) endRun(cp *runCheckpoint) {
+	if cp != nil {
+		markLive(cp.runID, false)
+	}
+}
+
+func markLive(id int64, live bool) {
+	liveRuns.Lock()
+	defer liveRuns.Unlock()
+	if live {
+		liveRuns.ids[id] = true
+	} else {
+		delete(liveRuns.ids, id)
+	}
+}
+
+func liveRunIDs() []int64 {
+	liveRuns.Lock()
+	defer liveRuns.Unlock()
+	ids := make([]int64, 0, len(liveRuns.ids))
+	for id := range liveRuns.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// interruptRun keeps a cut-short run for resuming and records how far it got
+func (s *Scheduler) interruptRun(cp *runCheckpoint, reason string) {
+	if cp == nil {
+		return
+	}
+	if err := s.store.InterruptScanRun(cp.runID); err != nil {
+		fmt.Printf("[Scheduler] Error saving interrupted run: %v\n", err)
+		return
+	}
+	run, err := s.store.GetScanRun(cp.runID)
+	if err != nil {
+		fmt.Printf("[Scheduler] Error loading interrupted run: %v\n", err)
+		return
+	}
+	s.recordPartial(run, reason)
+}
+
+// recordPartial writes a "PARTIAL" audit entry with the progress of a run
+func (s *Scheduler) recordPartial(run storage.ScanRun, reason string) {
+	hostname, _ := os.Hostname()
+	entry := storage.AuditEntry{
+		Timestamp:  time.Now().Format(time.RFC3339),
+		TotalFiles: run.FilesDone,
+		RiskScore:  run.RiskScore,
+		User:       hostname,
+		Status:     "PARTIAL",
+		Job:        run.JobName,
+		Note: fmt.Sprintf("%s after %d of %d files (scan path %d of %d); the next run of this job resumes it",
+			reason, run.FilesDone, run.Total, run.PathIndex+1, len(run.Paths)),
+	}
+	if err := s.store.AddAuditEntry(entry); err != nil {
+		fmt.Printf("[Scheduler] Error recording partial scan: %v\n", err)
+	}
+	s.notify("scan:scheduled:partial", map[string]interface{}{
+		"runId":      run.ID,
+		"job":        run.JobName,
+		"filesDone":  run.FilesDone,
+		"total":      run.Total,
+		"risk_score": run.RiskScore,
+	})
+}
+
+// recoverInterruptedRuns records runs a crash or forced quit left behind.
+// A run a previous scheduler is still finishing is skipped: it records its
+// own outcome when its scan returns.
+func (s *Scheduler) recoverInterruptedRuns() {
+	runs, err := s.store.InterruptRunningScans(liveRunIDs())
+	if err != nil {
+		fmt.Printf("[Scheduler] Error checking for interrupted scans: %v\n", err)
+		return
+	}
+	for _, run := range runs {
+		fmt.Printf("[Scheduler] Run %d of job %q was interrupted at %d of %d files\n", run.ID, run.JobName, run.FilesDone, run.Total)
+		s.recordPartial(run, "Interrupted unexpectedly")
+	}
+}
+
+func samePaths(a, b []string) bool {
+	x := append([]string(nil), a...)
+	y := append([]string(nil), b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return strings.Join(x, "\x00") == strings.Join(y, "\x00")
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	ny := newYork(t)
+	at := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, ny)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"business hours within the day", "0 9-17 * * MON-FRI", at(2026, time.March, 6, 12, 30), at(2026, time.March, 6, 13, 0)},
+		{"business hours over the weekend", "0 9-17 * * MON-FRI", at(2026, time.March, 6, 17, 0), at(2026, time.March, 9, 9, 0)},
+		{"steps and lists", "*/20 8,20 * * *", at(2026, time.March, 3, 8, 40), at(2026, time.March, 3, 20, 0)},
+		{"day of month only", "0 0 31 * *", at(2026, time.January, 31, 0, 0), at(2026, time.March, 31, 0, 0)},
+		{"day of week only", "0 6 * * SUN", at(2026, time.March, 2, 0, 0), at(2026, time.March, 8, 6, 0)},
+		{"7 is Sunday", "0 6 * * 7", at(2026, time.March, 2, 0, 0), at(2026, time.March, 8, 6, 0)},
+		// Both day fields restricted: either one qualifies
+		{"day of month before weekday", "0 0 1 * MON", at(2026, time.February, 24, 0, 0), at(2026, time.March, 1, 0, 0)},
+		{"weekday before day of month", "0 0 1 * MON", at(2026, time.March, 1, 0, 0), at(2026, time.March, 2, 0, 0)},
+		{"month names", "0 12 15 JUN,DEC *", at(2026, time.June, 15, 12, 0), at(2026, time.December, 15, 12, 0)},
+		{"leap day", "0 0 29 2 *", at(2026, time.March, 1, 0, 0), at(2028, time.February, 29, 0, 0)},
+		{"never fires", "0 0 30 2 *", at(2026, time.January, 1, 0, 0), time.Time{}},
+		{"macro", "@weekly", at(2026, time.March, 2, 0, 0), at(2026, time.March, 8, 0, 0)},
+		// 02:30 does not exist on 2026-03-08 in New York
+		{"spring-forward gap is skipped", "30 2 * * *", at(2026, time.March, 7, 3, 0), at(2026, time.March, 9, 2, 30)},
+		{"hourly across the gap", "30 * * * *", at(2026, time.March, 8, 1, 30), at(2026, time.March, 8, 3, 30)},
+		// 01:30 happens twice on 2026-11-01 in New York
+		{"fall-back runs at the first occurrence", "30 1 * * *", at(2026, time.October, 31, 12, 0), at(2026, time.November, 1, 1, 30)},
+		{"fall-back runs once", "30 1 * * *", at(2026, time.November, 1, 1, 30), at(2026, time.November, 2, 1, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr, "America/New_York")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("%q Next(%s) = %s, want %s", tt.expr, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * FOO *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@fortnightly",
+	} {
+		if _, err := ParseCron(expr, ""); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+	if _, err := ParseCron("@daily", "Mars/Olympus"); err == nil {
+		t.Error("ParseCron accepted an unknown timezone")
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"encoding/json"
+
+	"hipaa-app/internal/risk"
+	"hipaa-app/internal/storage"
+)
+
+// fingerprintCache adapts the SQLite fingerprint table to risk.ResultCache
+type fingerprintCache struct {
+	store *storage.Store
+}
+
+func (c fingerprintCache) Lookup(path string) (risk.CachedResult, bool) {
+	fp, ok := c.store.GetFingerprint(path)
+	if !ok {
+		return risk.CachedResult{}, false
+	}
+	result := risk.CachedResult{
+		Size:        fp.Size,
+		ModTime:     fp.ModTime,
+		ContentHash: fp.ContentHash,
+		Ruleset:     fp.Ruleset,
+	}
+	if err := json.Unmarshal(fp.Result, &result.Profile); err != nil {
+		return risk.CachedResult{}, false
+	}
+	if len(fp.Archive) > 0 {
+		result.Archive = &risk.ArchiveResult{}
+		if err := json.Unmarshal(fp.Archive, result.Archive); err != nil {
+			return risk.CachedResult{}, false
+		}
+	}
+	return result, true
+}
+
+func (c fingerprintCache) Store(path string, result risk.CachedResult) error {
+	data, err := json.Marshal(result.Profile)
+	if err != nil {
+		return err
+	}
+	fp := storage.FileFingerprint{
+		Path:        path,
+		Size:        result.Size,
+		ModTime:     result.ModTime,
+		ContentHash: result.ContentHash,
+		Ruleset:     result.Ruleset,
+		Result:      data,
+	}
+	if result.Archive != nil {
+		if fp.Archive, err = json.Marshal(result.Archive); err != nil {
+			return err
+		}
+	}
+	return c.store.PutFingerprint(fp)
+}
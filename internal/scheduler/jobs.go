@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"hipaa-app/internal/storage"
+)
+
+// DefaultJobName labels the job built from the legacy schedule settings
+const DefaultJobName = "Default"
+
+// job is a scan job as the runner sees it: a trigger plus what to scan and
+// whom to tell. ID 0 is the default job derived from ScheduleConfig.
+type job struct {
+	id         int64
+	name       string
+	trigger    Trigger // nil for a job that only runs manually
+	paths      []string
+	exclusions []string
+	notify     string
+	catchUp    string
+	next       time.Time
+	lastRun    time.Time
+}
+
+// JobRun describes when a job will next start
+type JobRun struct {
+	JobID   int64     `json:"jobId"`
+	Name    string    `json:"name"`
+	NextRun time.Time `json:"nextRun"`
+	LastRun time.Time `json:"lastRun"` // Zero if the job never ran
+}
+
+// ValidateJob checks a job's cron expression and timezone before it is stored
+func ValidateJob(j storage.ScanJob) error {
+	cron, err := ParseCron(j.Cron, j.Timezone)
+	if err != nil {
+		return err
+	}
+	if cron.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron expression %q never fires", j.Cron)
+	}
+	if len(j.Paths) == 0 {
+		return fmt.Errorf("scan job %q has no paths", j.Name)
+	}
+	return storage.ValidateCatchUp(j.CatchUp)
+}
+
+// defaultJob turns the legacy schedule settings into a job. The trigger is
+// nil when the schedule is off or invalid; the job can still run manually.
+func defaultJob(config *storage.ScheduleConfig, now time.Time) *job {
+	j := &job{
+		name:    DefaultJobName,
+		paths:   config.ScanPaths,
+		notify:  storage.NotifyOnRisk,
+		catchUp: config.CatchUp,
+	}
+	if config.Enabled {
+		schedule, err := ScheduleFromConfig(config, now)
+		if err != nil {
+			fmt.Printf("[Scheduler] Invalid default schedule, scheduled execution paused: %v\n", err)
+		} else {
+			j.trigger = schedule
+		}
+	}
+	return j
+}
+
+// storedJob turns a stored scan job into a runnable one
+func storedJob(sj storage.ScanJob) (*job, error) {
+	cron, err := ParseCron(sj.Cron, sj.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("scan job %q: %w", sj.Name, err)
+	}
+	return &job{
+		id:         sj.ID,
+		name:       sj.Name,
+		trigger:    cron,
+		paths:      sj.Paths,
+		exclusions: sj.Exclusions,
+		notify:     sj.Notify,
+		catchUp:    sj.CatchUp,
+	}, nil
+}
+
+// loadJobs builds the scheduled jobs: the default job when its schedule is
+// on, followed by every enabled stored job with a valid cron expression. The
+// persisted due time is kept, so a restart neither moves the next run nor
+// forgets one that was missed; editing a schedule clears it. An interval
+// schedule keeps its persisted anchor for the same reason.
+func (s *Scheduler) loadJobs(now time.Time) ([]*job, error) {
+	config, err := s.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*job
+	if def := defaultJob(config, now); def.trigger != nil {
+		jobs = append(jobs, def)
+	}
+
+	stored, err := s.store.ListJobs()
+	if err != nil {
+		return jobs, err
+	}
+	for _, sj := range stored {
+		if !sj.Enabled {
+			continue
+		}
+		j, err := storedJob(sj)
+		if err != nil {
+			fmt.Printf("[Scheduler] Skipping job: %v\n", err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+
+	for _, j := range jobs {
+		state, err := s.store.GetJobState(j.id)
+		if err != nil {
+			fmt.Printf("[Scheduler] Error loading state of job %q: %v\n", j.name, err)
+		}
+		j.lastRun = state.LastRun
+		if sc, ok := j.trigger.(Schedule); ok {
+			if state.Anchor.IsZero() {
+				if err := s.store.SetJobAnchor(j.id, sc.Anchor); err != nil {
+					fmt.Printf("[Scheduler] Error saving state of job %q: %v\n", j.name, err)
+				}
+			} else {
+				sc.Anchor = state.Anchor
+				j.trigger = sc
+			}
+		}
+		if !state.NextDue.IsZero() {
+			j.next = state.NextDue
+			continue
+		}
+		j.next = j.trigger.Next(now)
+		if err := s.store.SetJobNextDue(j.id, j.next); err != nil {
+			fmt.Printf("[Scheduler] Error saving state of job %q: %v\n", j.name, err)
+		}
+	}
+	return jobs, nil
+}
+
+// jobByID loads a job for a manual run, ignoring whether it is enabled
+func (s *Scheduler) jobByID(id int64) (*job, error) {
+	if id == 0 {
+		config, err := s.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		return defaultJob(config, time.Now()), nil
+	}
+	sj, err := s.store.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return storedJob(sj)
+}
+
+// UpcomingRuns lists each scheduled job with its next start, soonest first
+func (s *Scheduler) UpcomingRuns() []JobRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := []JobRun{}
+	for _, j := range s.jobs {
+		if !j.next.IsZero() {
+			runs = append(runs, JobRun{JobID: j.id, Name: j.name, NextRun: j.next, LastRun: j.lastRun})
+		}
+	}
+	sort.Slice(runs, func(i, k int) bool { return runs[i].NextRun.Before(runs[k].NextRun) })
+	return runs
+}
@@ -6,24 +6,31 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 	
+	"hipaa-app/internal/pathfilter"
 	"hipaa-app/internal/pdf"
 	"hipaa-app/internal/risk"
 	"hipaa-app/internal/storage"
 )
 
-// Scheduler manages background scan tasks
+// Scheduler runs scan jobs: the default job from the schedule settings and
+// any number of named cron jobs, one at a time, off a single timer
 type Scheduler struct {
 	riskEngine        *risk.RiskEngine
 	pdfService        *pdf.PDFService
 	store             *storage.Store
-	ticker            *time.Ticker
+	timer             *time.Timer
+	jobs              []*job // scheduled jobs with their next run
+	nextRun           time.Time
 	stop              chan bool
 	eventEmitter      func(string, interface{})
-	trigger           chan bool
+	trigger           chan int64 // job ID to run now
+	reload            chan bool
+	done              chan struct{} // closed when the run loop exits
 	cancelCurrentScan context.CancelFunc
 	mu                sync.Mutex
 }
@@ -36,7 +43,8 @@ func NewScheduler(engine *risk.RiskEngine, pdfService *pdf.PDFService, store *st
 		store:        store,
 		stop:         make(chan bool),
 		eventEmitter: emitEvent,
-		trigger:      make(chan bool, 1),
+		trigger:      make(chan int64, 1),
+		reload:       make(chan bool, 1),
 	}
 }
 
@@ -49,33 +57,126 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		return err
 	}
 	
-	fmt.Printf("[Scheduler] Initial config: Enabled=%v, Interval=%d hours, Paths=%v\n", config.Enabled, config.IntervalHours, config.ScanPaths)
+	fmt.Printf("[Scheduler] Initial config: Enabled=%v, Every=%d %s at %s %s, Paths=%v\n",
+		config.Enabled, config.IntervalValue, config.IntervalUnit, config.TimeOfDay, config.Timezone, config.ScanPaths)
 
-	// Always start the run loop to listen for manual triggers
-	// If disabled, just don't set a ticker yet, or set a long one/stop it immediately
+	// Always start the run loop to listen for manual triggers.
+	// The timer only fires when at least one job is scheduled.
+	s.timer = time.NewTimer(time.Hour)
+	s.timer.Stop()
 	
-	s.ticker = time.NewTicker(24 * time.Hour) // Default dummy ticker
-	if !config.Enabled || config.IntervalHours == 0 {
-		s.ticker.Stop() // Stop ticker if disabled, run loop will block on select but still listen to trigger
+	s.recoverInterruptedRuns()
+	s.loadAndArm()
+	if s.NextRunAt().IsZero() {
 		fmt.Println("[Scheduler] Scheduled execution paused (Waiting for specific time or manual trigger)")
-	} else {
-		interval := time.Duration(config.IntervalHours) * time.Hour
-		s.ticker.Reset(interval)
 	}
 	
-	go s.run(ctx, config)
+	s.done = make(chan struct{})
+	go s.run(ctx)
 	
 	return nil
 }
 
-// Stop gracefully stops the scheduler
+// NextRunAt returns when the next scheduled scan will start, or the zero
+// time when scheduled runs are off
+func (s *Scheduler) NextRunAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextRun
+}
+
+// Reload re-reads the jobs from storage, e.g. after one was added or edited
+func (s *Scheduler) Reload() {
+	select {
+	case s.reload <- true:
+	default: // Reload already pending
+	}
+}
+
+// loadAndArm replaces the scheduled jobs with the stored ones
+func (s *Scheduler) loadAndArm() {
+	jobs, err := s.loadJobs(time.Now())
+	if err != nil {
+		fmt.Printf("[Scheduler] Error loading jobs: %v\n", err)
+	}
+	s.mu.Lock()
+	s.jobs = jobs
+	s.mu.Unlock()
+	fmt.Printf("[Scheduler] %d scheduled job(s)\n", len(jobs))
+	s.armTimer()
+}
+
+// armTimer sets the timer for the soonest job
+func (s *Scheduler) armTimer() {
+	s.mu.Lock()
+	var soonest *job
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && (soonest == nil || j.next.Before(soonest.next)) {
+			soonest = j
+		}
+	}
+	if soonest == nil {
+		s.nextRun = time.Time{}
+		s.timer.Stop()
+		s.mu.Unlock()
+		return
+	}
+	next := soonest.next
+	s.nextRun = next
+	s.timer.Reset(time.Until(next))
+	s.mu.Unlock()
+	
+	fmt.Printf("[Scheduler] Next scheduled run: %s (%s)\n", next.Format(time.RFC1123), soonest.name)
+	s.notify("scan:scheduled:next", map[string]interface{}{
+		"nextRun": next.Format(time.RFC3339),
+		"job":     soonest.name,
+	})
+}
+
+// dueJobs returns the jobs whose next run is at or before now
+func (s *Scheduler) dueJobs(now time.Time) []*job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*job
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && !j.next.After(now) {
+			due = append(due, j)
+		}
+	}
+	sort.Slice(due, func(i, k int) bool { return due[i].next.Before(due[k].next) })
+	return due
+}
+
+// reschedule moves a job's next run past t and persists it
+func (s *Scheduler) reschedule(j *job, t time.Time) {
+	s.mu.Lock()
+	j.next = j.trigger.Next(t)
+	next := j.next
+	s.mu.Unlock()
+	if err := s.store.SetJobNextDue(j.id, next); err != nil {
+		fmt.Printf("[Scheduler] Error saving state of job %q: %v\n", j.name, err)
+	}
+}
+
+// stopTimeout bounds how long Stop waits for a running scan to checkpoint
+const stopTimeout = 10 * time.Second
+
+// Stop gracefully stops the scheduler. A running scan is cancelled and kept
+// for resuming; Stop waits briefly so its checkpoint is saved before exit.
 func (s *Scheduler) Stop() {
 	fmt.Println("[Scheduler] Stopping...")
-	if s.ticker != nil {
-		s.ticker.Stop()
+	if s.timer != nil {
+		s.timer.Stop()
 	}
 	s.CancelScan() // Cancel any running scan
 	close(s.stop)
+	if s.done != nil {
+		select {
+		case <-s.done:
+		case <-time.After(stopTimeout):
+			fmt.Println("[Scheduler] Timed out waiting for the running scan to stop")
+		}
+	}
 }
 
 // CancelScan cancels the currently running scan if any
@@ -90,12 +191,17 @@ func (s *Scheduler) CancelScan() {
 	}
 }
 
-// RunNow immediately triggers a scheduled scan (manual trigger)
+// RunNow immediately triggers the default job (manual trigger)
 func (s *Scheduler) RunNow() {
-	fmt.Println("[Scheduler] RunNow triggered")
+	s.RunJobNow(0)
+}
+
+// RunJobNow immediately triggers a job by ID, whether or not it is enabled
+func (s *Scheduler) RunJobNow(id int64) {
+	fmt.Printf("[Scheduler] RunNow triggered for job %d\n", id)
 	if s.trigger != nil {
 		select {
-		case s.trigger <- true:
+		case s.trigger <- id:
 			// Trigger sent successfully
 		default:
 			// Trigger already pending or channel full, ignore
@@ -104,45 +210,59 @@ func (s *Scheduler) RunNow() {
 	}
 }
 
-func (s *Scheduler) run(ctx context.Context, initialConfig *storage.ScheduleConfig) {
+func (s *Scheduler) run(ctx context.Context) {
 	fmt.Println("[Scheduler] Run loop started")
-	// Run once immediately ONLY IF ENABLED
-	if initialConfig.Enabled && initialConfig.IntervalHours > 0 {
-		s.executeScan(ctx, initialConfig.ScanPaths)
-	}
+	defer close(s.done)
+	
+	wake := time.NewTicker(wakeCheckInterval)
+	defer wake.Stop()
+	lastCheck := time.Now()
 	
 	for {
 		select {
-		case <-s.ticker.C:
-			fmt.Println("[Scheduler] Ticker fired")
-			// Reload config in case it changed
-			config, err := s.store.Load()
-			if err != nil || !config.Enabled {
-				continue
+		case <-s.timer.C:
+			fmt.Println("[Scheduler] Timer fired")
+			// Jobs run one after another, catching up on any missed runs
+			s.runDue(ctx, time.Now())
+			s.armTimer()
+			lastCheck = time.Now()
+			
+		case <-wake.C:
+			// The timer runs on the monotonic clock and fires late after a
+			// suspend; check the wall clock so missed jobs run on wake
+			if woke(lastCheck, time.Now()) {
+				fmt.Println("[Scheduler] Wake from sleep detected")
+				s.runDue(ctx, time.Now())
+				s.armTimer()
 			}
-			s.executeScan(ctx, config.ScanPaths)
+			lastCheck = time.Now()
 			
-		case <-s.trigger:
+		case id := <-s.trigger:
 			fmt.Println("[Scheduler] Manual trigger received in run loop")
-			// Manual trigger - reload config to get latest paths, but IGNORE enabled status
-			config, err := s.store.Load()
+			// Manual trigger - reload the job to get latest paths, but IGNORE enabled status
+			j, err := s.jobByID(id)
 			if err != nil {
-				fmt.Printf("[Scheduler] Cannot run: Error loading config=%v\n", err)
+				fmt.Printf("[Scheduler] Cannot run: Error loading job=%v\n", err)
 				continue
 			}
-			fmt.Println("[Scheduler] Executing manual scan...")
-			s.executeScan(ctx, config.ScanPaths)
+			fmt.Printf("[Scheduler] Executing manual scan of job %q...\n", j.name)
+			s.runJob(ctx, j, nil)
+			lastCheck = time.Now()
+			
+		case <-s.reload:
+			s.loadAndArm()
 
 		case <-s.stop:
 			fmt.Println("[Scheduler] Stop signal received")
-			s.ticker.Stop()
+			s.timer.Stop()
 			return
 		}
 	}
 }
 
-func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
-	fmt.Printf("[Scheduler] executeScan calling for paths: %v\n", paths)
+func (s *Scheduler) executeScan(parentCtx context.Context, j *job, covered []time.Time) {
+	paths := j.paths
+	fmt.Printf("[Scheduler] executeScan calling job %q for paths: %v\n", j.name, paths)
 	if len(paths) == 0 {
 		fmt.Println("[Scheduler] No paths to scan")
 		return
@@ -161,17 +281,17 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 		cancel()
 	}()
 	
-	// Supported file extensions (must match risk/engine.go)
-	isScannable := func(ext string) bool {
-		switch ext {
-		case ".txt", ".csv", ".log", ".md", ".json", ".xml", ".html", ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".rtf",
-			".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tiff", ".tif":
-			return true
-		}
-		return false
+	// Global and per-path rules from the settings, plus the job's exclusions
+	config, err := s.store.Load()
+	if err != nil {
+		fmt.Printf("[Scheduler] Error loading scan rules, using defaults: %v\n", err)
+		config = &storage.ScheduleConfig{}
+	}
+	rules := func(root string) pathfilter.Rules {
+		return config.RulesFor(root).Merge(pathfilter.Rules{Exclude: j.exclusions})
 	}
 	
-	s.notify("log:info", fmt.Sprintf("Starting scan of %d directories...", len(paths)))
+	s.notify("log:info", fmt.Sprintf("Starting %s scan of %d directories...", j.name, len(paths)))
 
 	// First, count total files to scan
 	totalToScan := 0
@@ -188,7 +308,8 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 		}
 		
 		fmt.Printf("[Scheduler] Walking path: %s\n", path)
-		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		// Same rules as the analysis walk, so the progress total matches
+		pathfilter.New(path, rules(path)).Walk(func(p string, d fs.DirEntry) error {
 			// Check for cancellation per file
 			select {
 			case <-ctx.Done():
@@ -196,17 +317,7 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 			default:
 			}
 
-			if err != nil {
-				// Log permission errors etc but continue
-				fmt.Printf("[Scheduler] Error accessing %s: %v\n", p, err)
-				return nil
-			}
-			if d.IsDir() {
-				return nil
-			}
-			if isScannable(strings.ToLower(filepath.Ext(p))) {
-				totalToScan++
-			}
+			totalToScan++
 			return nil
 		})
 		
@@ -220,6 +331,7 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 	
 	fmt.Printf("[Scheduler] Total files to scan: %d\n", totalToScan)
 	s.notify("scan:scheduled:start", map[string]interface{}{
+		"job":   j.name,
 		"paths": paths,
 		"total": totalToScan,
 	})
@@ -228,6 +340,17 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 	totalRisk := 0
 	scannedCount := 0
 	var riskyFiles []map[string]interface{}
+	var combined risk.AuditReport // de-duplicates individuals across scan paths
+	
+	// Unchanged files reuse their last result from the fingerprint table;
+	// finished files are checkpointed so an interrupted scan can resume
+	scanOpts := s.riskEngine.ScanOptions()
+	scanOpts.Cache = fingerprintCache{store: s.store}
+	checkpoint := s.beginRun(j, totalToScan)
+	defer s.endRun(checkpoint)
+	if checkpoint != nil {
+		scanOpts.Checkpoint = checkpoint
+	}
 	
 	// Progress callback for per-file updates
 	progressCallback := func(filePath string) {
@@ -242,13 +365,18 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 		})
 	}
 	
-	for _, path := range paths {
+	for i, path := range paths {
 		fmt.Printf("[Scheduler] Analyzing directory: %s\n", path)
-		report, err := s.riskEngine.AnalyzeDirectory(ctx, path, progressCallback)
+		if checkpoint != nil {
+			s.store.SetScanRunPathIndex(checkpoint.runID, i)
+		}
+		scanOpts.Rules = rules(path)
+		report, err := s.riskEngine.AnalyzeDirectoryWithOptions(ctx, path, scanOpts, progressCallback)
 		if err != nil {
 			if err == context.Canceled {
-				fmt.Println("[Scheduler] Analysis cancelled")
-				s.notify("scan:scheduled:error", map[string]interface{}{"error": "Scan cancelled by user"})
+				fmt.Println("[Scheduler] Analysis cancelled, progress kept for resuming")
+				s.interruptRun(checkpoint, "Stopped")
+				s.notify("scan:scheduled:error", map[string]interface{}{"error": "Scan cancelled by user", "resumable": checkpoint != nil})
 				return
 			}
 			fmt.Printf("[Scheduler] Error analyzing %s: %v\n", path, err)
@@ -258,6 +386,7 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 		
 		totalFiles += report.TotalFiles
 		totalRisk += report.TotalRiskScore
+		combined.Merge(report)
 		
 		// Collect risky files for frontend
 		for _, offender := range report.TopOffenders {
@@ -266,11 +395,20 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 					"path":      offender.FilePath,
 					"riskScore": offender.RiskScore,
 					"findings":  offender.Findings,
+					"details":   offender.Details,
+					"uniqueIndividuals": offender.UniqueIndividuals,
 				})
 			}
 		}
 	}
 	
+	// The audit entry below supersedes the checkpoint
+	if checkpoint != nil {
+		if err := s.store.DeleteScanRun(checkpoint.runID); err != nil {
+			fmt.Printf("[Scheduler] Error clearing checkpoint: %v\n", err)
+		}
+	}
+	
 	// Record audit entry
 	status := "PASSED"
 	certPath := ""
@@ -305,20 +443,37 @@ func (s *Scheduler) executeScan(parentCtx context.Context, paths []string) {
 		RiskScore:  totalRisk,
 		User:       hostname,
 		Status:     status,
+		Job:        j.name,
+	}
+	var notes []string
+	if n := len(covered); n > 0 {
+		notes = append(notes, fmt.Sprintf("Catch-up scan covering %d missed scheduled run(s) between %s and %s",
+			n, covered[0].Format(time.RFC3339), covered[n-1].Format(time.RFC3339)))
+	}
+	if n := len(combined.Unscanned); n > 0 {
+		// Unsupported or unreadable files need a manual review
+		notes = append(notes, fmt.Sprintf("%d file(s) not scanned", n))
 	}
+	entry.Note = strings.Join(notes, "; ")
 	
 	s.store.AddAuditEntry(entry)
 	
 	// Create map for notification
 	notifyData := map[string]interface{}{
+		"job":         j.name,
+		"notify":      j.notify,
 		"status":      status,
 		"total_files": totalFiles,
 		"risk_score":  totalRisk,
 		"risky_files": riskyFiles,
 		"certificate": certPath,
+		"estimated_individuals": combined.EstimatedIndividuals,
+		"notify_hhs":  combined.NotifyHHS,
+		"liability":   combined.Liability,
+		"unscanned":   combined.Unscanned,
 	}
 
-	fmt.Printf("[Scheduler] Scan complete. Status: %s, Files: %d, Risk: %d\n", status, totalFiles, totalRisk)
+	fmt.Printf("[Scheduler] Scan of job %q complete. Status: %s, Files: %d, Risk: %d, Individuals: %d, Cached: %d, Resumed: %d, Unscanned: %d\n", j.name, status, totalFiles, totalRisk, combined.EstimatedIndividuals, combined.CachedFiles, combined.ResumedFiles, len(combined.Unscanned))
 	s.notify("scan:scheduled:complete", notifyData)
 }
 
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"hipaa-app/internal/pathfilter"
+)
+
+// Notification policies for a scan job
+const (
+	NotifyAlways = "always"  // Alert after every run
+	NotifyOnRisk = "on_risk" // Alert only when a run finds risks
+	NotifyNever  = "never"   // Record the audit entry silently
+)
+
+// Catch-up policies for runs missed while the app was closed or asleep
+const (
+	CatchUpOnce = "once" // Run one catch-up scan for any number of missed runs
+	CatchUpAll  = "all"  // Run one catch-up scan whose audit entry covers every missed run
+	CatchUpSkip = "skip" // Wait for the next scheduled run
+)
+
+// ScanJob is a named scan with its own cron schedule, paths and exclusions
+type ScanJob struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Cron       string   `json:"cron"`     // Five-field cron expression or macro ("@daily")
+	Timezone   string   `json:"timezone"` // IANA zone; empty means local time
+	Paths      []string `json:"paths"`
+	Exclusions []string `json:"exclusions"` // Extra exclude patterns (.gitignore syntax or full paths, see pathfilter.Rules)
+	Notify     string   `json:"notify"`     // NotifyAlways, NotifyOnRisk or NotifyNever
+	CatchUp    string   `json:"catch_up"`   // CatchUpOnce, CatchUpAll or CatchUpSkip
+	Enabled    bool     `json:"enabled"`
+}
+
+// ListJobs returns every stored scan job ordered by name
+func (s *Store) ListJobs() ([]ScanJob, error) {
+	rows, err := s.db.Query(`SELECT id, name, cron, timezone, notify, catch_up, enabled FROM scan_jobs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	jobs := []ScanJob{}
+	for rows.Next() {
+		var job ScanJob
+		if err := rows.Scan(&job.ID, &job.Name, &job.Cron, &job.Timezone, &job.Notify, &job.CatchUp, &job.Enabled); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		if err := s.loadJobLists(&jobs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// GetJob returns one scan job by ID
+func (s *Store) GetJob(id int64) (ScanJob, error) {
+	job := ScanJob{ID: id}
+	err := s.db.QueryRow(`SELECT name, cron, timezone, notify, catch_up, enabled FROM scan_jobs WHERE id = ?`, id).
+		Scan(&job.Name, &job.Cron, &job.Timezone, &job.Notify, &job.CatchUp, &job.Enabled)
+	if err == sql.ErrNoRows {
+		return ScanJob{}, fmt.Errorf("scan job %d not found", id)
+	}
+	if err != nil {
+		return ScanJob{}, err
+	}
+	return job, s.loadJobLists(&job)
+}
+
+// loadJobLists fills in a job's paths and exclusions
+func (s *Store) loadJobLists(job *ScanJob) error {
+	var err error
+	if job.Paths, err = s.queryStrings(`SELECT path FROM scan_job_paths WHERE job_id = ? ORDER BY path`, job.ID); err != nil {
+		return err
+	}
+	job.Exclusions, err = s.queryStrings(`SELECT pattern FROM scan_job_exclusions WHERE job_id = ? ORDER BY pattern`, job.ID)
+	return err
+}
+
+func (s *Store) queryStrings(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// SaveJob inserts a job (ID 0) or replaces an existing one and returns its ID.
+// The cron expression is validated by the scheduler before it gets here.
+func (s *Store) SaveJob(job ScanJob) (int64, error) {
+	job.Name = strings.TrimSpace(job.Name)
+	if job.Name == "" {
+		return 0, fmt.Errorf("scan job name is required")
+	}
+	switch job.Notify {
+	case "":
+		job.Notify = NotifyOnRisk
+	case NotifyAlways, NotifyOnRisk, NotifyNever:
+	default:
+		return 0, fmt.Errorf("unknown notification policy %q", job.Notify)
+	}
+	if err := ValidateCatchUp(job.CatchUp); err != nil {
+		return 0, err
+	}
+	if job.CatchUp == "" {
+		job.CatchUp = CatchUpOnce
+	}
+	for _, pattern := range job.Exclusions {
+		if !appliesToAny(pattern, job.Paths) {
+			return 0, fmt.Errorf("exclusion %q is a full path outside the job's folders", pattern)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if job.ID == 0 {
+		res, err := tx.Exec(`INSERT INTO scan_jobs (name, cron, timezone, notify, catch_up, enabled) VALUES (?, ?, ?, ?, ?, ?)`,
+			job.Name, job.Cron, job.Timezone, job.Notify, job.CatchUp, job.Enabled)
+		if err != nil {
+			return 0, err
+		}
+		if job.ID, err = res.LastInsertId(); err != nil {
+			return 0, err
+		}
+	} else {
+		res, err := tx.Exec(`UPDATE scan_jobs SET name = ?, cron = ?, timezone = ?, notify = ?, catch_up = ?, enabled = ? WHERE id = ?`,
+			job.Name, job.Cron, job.Timezone, job.Notify, job.CatchUp, job.Enabled, job.ID)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return 0, fmt.Errorf("scan job %d not found", job.ID)
+		}
+		// The schedule may have changed: count the next run from now rather
+		// than treating runs due under the old one as missed
+		if _, err := tx.Exec(`UPDATE scan_job_state SET next_due = 0, anchor = 0 WHERE job_id = ?`, job.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM scan_job_paths WHERE job_id = ?`, job.ID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM scan_job_exclusions WHERE job_id = ?`, job.ID); err != nil {
+		return 0, err
+	}
+	for _, p := range job.Paths {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO scan_job_paths (job_id, path) VALUES (?, ?)`, job.ID, p); err != nil {
+			return 0, err
+		}
+	}
+	for _, pattern := range job.Exclusions {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO scan_job_exclusions (job_id, pattern) VALUES (?, ?)`, job.ID, pattern); err != nil {
+			return 0, err
+		}
+	}
+	return job.ID, tx.Commit()
+}
+
+// appliesToAny reports whether an exclude pattern can match beneath at least
+// one of the paths; only a full path elsewhere on disk cannot
+func appliesToAny(pattern string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if _, ok := pathfilter.AnchorTo(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteJob removes a scan job with its paths, exclusions and interrupted
+// runs, which could no longer be resumed
+func (s *Store) DeleteJob(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM scan_run_files WHERE run_id IN (SELECT id FROM scan_runs WHERE job_id = ?)`, id); err != nil {
+		return err
+	}
+	for _, table := range []string{"scan_runs", "scan_job_paths", "scan_job_exclusions", "scan_job_state"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE job_id = ?", id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM scan_jobs WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ValidateCatchUp checks a catch-up policy; empty means CatchUpOnce
+func ValidateCatchUp(policy string) error {
+	switch policy {
+	case "", CatchUpOnce, CatchUpAll, CatchUpSkip:
+		return nil
+	}
+	return fmt.Errorf("unknown catch-up policy %q", policy)
+}
+
+// JobState is when a job last ran and when its next run is due. Job ID 0 is
+// the default job built from the schedule settings.
+type JobState struct {
+	JobID   int64     `json:"jobId"`
+	LastRun time.Time `json:"lastRun"` // Zero if the job never ran
+	NextDue time.Time `json:"nextDue"` // Zero if not scheduled
+	Anchor  time.Time `json:"anchor"`  // First slot of an interval schedule; zero for cron jobs
+}
+
+// GetJobState returns the persisted run times of a job
+func (s *Store) GetJobState(id int64) (JobState, error) {
+	var lastRun, nextDue, anchor int64
+	err := s.db.QueryRow(`SELECT last_run, next_due, anchor FROM scan_job_state WHERE job_id = ?`, id).Scan(&lastRun, &nextDue, &anchor)
+	if err == sql.ErrNoRows {
+		return JobState{JobID: id}, nil
+	}
+	if err != nil {
+		return JobState{JobID: id}, err
+	}
+	return JobState{JobID: id, LastRun: unixTime(lastRun), NextDue: unixTime(nextDue), Anchor: unixTime(anchor)}, nil
+}
+
+// SetJobLastRun records when a job last started
+func (s *Store) SetJobLastRun(id int64, t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO scan_job_state (job_id, last_run) VALUES (?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET last_run = excluded.last_run`, id, unixSeconds(t))
+	return err
+}
+
+// SetJobNextDue records when a job's next run is due; the zero time clears it
+func (s *Store) SetJobNextDue(id int64, t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO scan_job_state (job_id, next_due) VALUES (?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET next_due = excluded.next_due`, id, unixSeconds(t))
+	return err
+}
+
+// SetJobAnchor records the first slot an interval schedule counts from
+func (s *Store) SetJobAnchor(id int64, t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO scan_job_state (job_id, anchor) VALUES (?, ?)
+		ON CONFLICT(job_id) DO UPDATE SET anchor = excluded.anchor`, id, unixSeconds(t))
+	return err
+}
+
+// ResetJobSchedule forgets a job's due time and anchor after its schedule
+// was edited, so the next run counts from now rather than from the old one
+func (s *Store) ResetJobSchedule(id int64) error {
+	_, err := s.db.Exec(`UPDATE scan_job_state SET next_due = 0, anchor = 0 WHERE job_id = ?`, id)
+	return err
+}
+
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
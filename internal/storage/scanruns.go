@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Scan run states. Finished runs are deleted; their audit entry is the record.
+const (
+	ScanRunRunning     = "running"
+	ScanRunInterrupted = "interrupted"
+)
+
+// ScanRun is the checkpoint of a scheduled scan that is in progress or was
+// cut short by cancellation, shutdown or a crash
+type ScanRun struct {
+	ID        int64     `json:"id"`
+	JobID     int64     `json:"jobId"`
+	JobName   string    `json:"jobName"`
+	Paths     []string  `json:"paths"`
+	PathIndex int       `json:"pathIndex"` // Scan path being analyzed when last checkpointed
+	Total     int       `json:"total"`     // Files counted before analysis began
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	FilesDone int       `json:"filesDone"` // Files with a recorded result
+	RiskScore int       `json:"riskScore"` // Sum over recorded files
+}
+
+// ScanRunFile is one finished file of a scan run. Result is the engine's
+// serialized profile for risky files and empty for clean ones, and Archive
+// the results of the files inside an archive or mail file; Size, ModTime
+// and Ruleset tell whether it still applies when the run is resumed.
+type ScanRunFile struct {
+	Path      string
+	RiskScore int
+	TimedOut  bool
+	Size      int64
+	ModTime   int64 // Unix nanoseconds
+	Ruleset   string
+	Result    []byte
+	Archive   []byte
+}
+
+// StartScanRun creates a running checkpoint for a job
+func (s *Store) StartScanRun(jobID int64, jobName string, paths []string, total int) (int64, error) {
+	data, err := json.Marshal(paths)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	res, err := s.db.Exec(`INSERT INTO scan_runs (job_id, job_name, paths, total, status, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, jobID, jobName, string(data), total, ScanRunRunning, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ResumeScanRun marks an interrupted run as running again with a fresh file count
+func (s *Store) ResumeScanRun(id int64, total int) error {
+	_, err := s.db.Exec(`UPDATE scan_runs SET status = ?, total = ?, updated_at = ? WHERE id = ?`,
+		ScanRunRunning, total, time.Now().Unix(), id)
+	return err
+}
+
+// SetScanRunPathIndex records which scan path a run has reached
+func (s *Store) SetScanRunPathIndex(id int64, index int) error {
+	_, err := s.db.Exec(`UPDATE scan_runs SET path_index = ?, updated_at = ? WHERE id = ?`, index, time.Now().Unix(), id)
+	return err
+}
+
+// InterruptScanRun marks a run as resumable
+func (s *Store) InterruptScanRun(id int64) error {
+	_, err := s.db.Exec(`UPDATE scan_runs SET status = ?, updated_at = ? WHERE id = ?`, ScanRunInterrupted, time.Now().Unix(), id)
+	return err
+}
+
+// InterruptRunningScans marks runs left "running" by a crash or forced quit
+// as interrupted and returns them. Runs listed in live are still being
+// scanned and are left alone.
+func (s *Store) InterruptRunningScans(live []int64) ([]ScanRun, error) {
+	runs, err := s.scanRuns(`WHERE status = ?`, ScanRunRunning)
+	if err != nil {
+		return nil, err
+	}
+	interrupted := []ScanRun{}
+	for _, run := range runs {
+		if containsID(live, run.ID) {
+			continue
+		}
+		if err := s.InterruptScanRun(run.ID); err != nil {
+			return nil, err
+		}
+		run.Status = ScanRunInterrupted
+		interrupted = append(interrupted, run)
+	}
+	return interrupted, nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+// InterruptedScanRuns lists the resumable runs, newest first
+func (s *Store) InterruptedScanRuns() ([]ScanRun, error) {
+	return s.scanRuns(`WHERE status = ?`, ScanRunInterrupted)
+}
+
+// GetScanRun returns one run with its progress
+func (s *Store) GetScanRun(id int64) (ScanRun, error) {
+	runs, err := s.scanRuns(`WHERE id = ?`, id)
+	if err != nil {
+		return ScanRun{}, err
+	}
+	if len(runs) == 0 {
+		return ScanRun{}, fmt.Errorf("scan run %d not found", id)
+	}
+	return runs[0], nil
+}
+
+// LatestInterruptedRun returns the newest resumable run of a job
+func (s *Store) LatestInterruptedRun(jobID int64) (ScanRun, bool, error) {
+	runs, err := s.scanRuns(`WHERE status = ? AND job_id = ?`, ScanRunInterrupted, jobID)
+	if err != nil || len(runs) == 0 {
+		return ScanRun{}, false, err
+	}
+	return runs[0], true, nil
+}
+
+func (s *Store) scanRuns(where string, args ...interface{}) ([]ScanRun, error) {
+	rows, err := s.db.Query(`SELECT r.id, r.job_id, r.job_name, r.paths, r.path_index, r.total, r.status, r.started_at, r.updated_at,
+			COUNT(f.path), COALESCE(SUM(f.risk_score), 0)
+		FROM scan_runs r LEFT JOIN scan_run_files f ON f.run_id = r.id `+where+`
+		GROUP BY r.id ORDER BY r.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []ScanRun{}
+	for rows.Next() {
+		var run ScanRun
+		var paths string
+		var started, updated int64
+		if err := rows.Scan(&run.ID, &run.JobID, &run.JobName, &paths, &run.PathIndex, &run.Total, &run.Status,
+			&started, &updated, &run.FilesDone, &run.RiskScore); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(paths), &run.Paths)
+		run.StartedAt, run.UpdatedAt = time.Unix(started, 0), time.Unix(updated, 0)
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// RecordScanRunFile checkpoints one finished file
+func (s *Store) RecordScanRunFile(runID int64, f ScanRunFile) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO scan_run_files (run_id, path, risk_score, timed_out, size, mtime, ruleset, result, archive)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, runID, f.Path, f.RiskScore, f.TimedOut, f.Size, f.ModTime, f.Ruleset, string(f.Result), string(f.Archive))
+	return err
+}
+
+// ScanRunFiles returns every file a run has checkpointed
+func (s *Store) ScanRunFiles(runID int64) ([]ScanRunFile, error) {
+	rows, err := s.db.Query(`SELECT path, risk_score, timed_out, size, mtime, ruleset, result, archive FROM scan_run_files WHERE run_id = ?`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []ScanRunFile{}
+	for rows.Next() {
+		var f ScanRunFile
+		var result, archive string
+		if err := rows.Scan(&f.Path, &f.RiskScore, &f.TimedOut, &f.Size, &f.ModTime, &f.Ruleset, &result, &archive); err != nil {
+			return nil, err
+		}
+		f.Result = []byte(result)
+		if archive != "" {
+			f.Archive = []byte(archive)
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// DeleteScanRun drops a run and its checkpointed files, once it has finished
+// or when the user discards it
+func (s *Store) DeleteScanRun(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM scan_run_files WHERE run_id = ?`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM scan_runs WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("scan run %d not found", id)
+	}
+	return tx.Commit()
+}
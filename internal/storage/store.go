@@ -8,29 +8,59 @@ import (
 	"path/filepath"
 	"time"
 
+	"hipaa-app/internal/liability"
+	"hipaa-app/internal/pathfilter"
+
 	_ "modernc.org/sqlite"
 )
 
+// FileFingerprint is the cached scan result of one file. Result is the
+// engine's serialized profile, or for an archive or mail file Archive holds
+// the results of the files inside; neither may contain raw PHI.
+type FileFingerprint struct {
+	Path        string
+	Size        int64
+	ModTime     int64 // Unix nanoseconds
+	ContentHash string
+	Ruleset     string
+	Result      []byte
+	Archive     []byte
+}
+
 // AuditEntry represents a single audit history record
 type AuditEntry struct {
 	Timestamp  string `json:"timestamp"`
 	TotalFiles int    `json:"total_files"`
 	RiskScore  int    `json:"risk_score"`
 	User       string `json:"user"`
-	Status     string `json:"status"` // "PASSED" or "FAILED"
+	Status     string `json:"status"`         // "PASSED", "FAILED" or "MISSED"
+	Job        string `json:"job,omitempty"`  // Scan job that produced the entry
+	Note       string `json:"note,omitempty"` // e.g. which runs a "MISSED" entry covers
+}
+
+// SameSchedule reports whether two configs schedule the default job the same
+// way, so its persisted due time and anchor still apply
+func (c *ScheduleConfig) SameSchedule(o *ScheduleConfig) bool {
+	return c.Enabled == o.Enabled && c.IntervalHours == o.IntervalHours &&
+		c.IntervalValue == o.IntervalValue && c.IntervalUnit == o.IntervalUnit &&
+		c.TimeOfDay == o.TimeOfDay && c.Timezone == o.Timezone
 }
 
 // ScheduleConfig holds the scheduler configuration and cumulative stats
 type ScheduleConfig struct {
-	Enabled          bool         `json:"schedule_enabled"`
-	IntervalHours    int          `json:"scan_interval_hours"` // Deprecated, kept for backwards compatibility
-	IntervalValue    int          `json:"interval_value"`      // New: 1, 2, 3, etc.
-	IntervalUnit     string       `json:"interval_unit"`       // New: "hours", "days", "weeks", "months"
-	TimeOfDay        string       `json:"time_of_day"`         // New: "14:30" (24-hour format)
-	Timezone         string       `json:"timezone"`            // New: "America/Chicago", etc.
-	ScanPaths        []string     `json:"scan_paths"`
-	AuditHistory     []AuditEntry `json:"audit_history"`
-	LastNotification time.Time    `json:"last_notification,omitempty"`
+	Enabled          bool                        `json:"schedule_enabled"`
+	IntervalHours    int                         `json:"scan_interval_hours"` // Deprecated, kept for backwards compatibility
+	IntervalValue    int                         `json:"interval_value"`      // New: 1, 2, 3, etc.
+	IntervalUnit     string                      `json:"interval_unit"`       // New: "hours", "days", "weeks", "months"
+	TimeOfDay        string                      `json:"time_of_day"`         // New: "14:30" (24-hour format)
+	Timezone         string                      `json:"timezone"`            // New: "America/Chicago", etc.
+	ScanPaths        []string                    `json:"scan_paths"`
+	AuditHistory     []AuditEntry                `json:"audit_history"`
+	LastNotification time.Time                   `json:"last_notification,omitempty"`
+	SentinelEnabled  bool                        `json:"sentinel_enabled"` // Real-time watcher on ScanPaths
+	CatchUp          string                      `json:"catch_up"`         // Missed-run policy: CatchUpOnce, CatchUpAll or CatchUpSkip
+	ScanRules        pathfilter.Rules            `json:"scan_rules"`       // Applied to every scan
+	PathRules        map[string]pathfilter.Rules `json:"path_rules"`       // Extra rules per scan path
 
 	// Cumulative Stats (persist across sessions)
 	TotalFilesScanned int `json:"total_files_scanned"`
@@ -59,8 +89,8 @@ func NewStore() (*Store, error) {
 	dbPath := filepath.Join(configDir, "guardian.db")
 	configPath := filepath.Join(configDir, "config.json")
 
-	// Open SQLite database
-	db, err := sql.Open("sqlite", dbPath)
+	// Open SQLite database (busy timeout so concurrent scan workers wait for writes)
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -111,12 +141,129 @@ func (s *Store) initSchema() error {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS liability_models (
+		version TEXT PRIMARY KEY,
+		model TEXT NOT NULL,
+		active INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS file_fingerprints (
+		path TEXT PRIMARY KEY,
+		size INTEGER NOT NULL,
+		mtime INTEGER NOT NULL,
+		content_hash TEXT NOT NULL,
+		ruleset TEXT NOT NULL,
+		result TEXT NOT NULL,
+		archive TEXT NOT NULL DEFAULT '',
+		scanned_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		cron TEXT NOT NULL,
+		timezone TEXT NOT NULL DEFAULT '',
+		notify TEXT NOT NULL DEFAULT 'on_risk',
+		catch_up TEXT NOT NULL DEFAULT 'once',
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_job_paths (
+		job_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		PRIMARY KEY (job_id, path)
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_job_exclusions (
+		job_id INTEGER NOT NULL,
+		pattern TEXT NOT NULL,
+		PRIMARY KEY (job_id, pattern)
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_job_state (
+		job_id INTEGER PRIMARY KEY,
+		last_run INTEGER NOT NULL DEFAULT 0,
+		next_due INTEGER NOT NULL DEFAULT 0,
+		anchor INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_id INTEGER NOT NULL,
+		job_name TEXT NOT NULL,
+		paths TEXT NOT NULL,
+		path_index INTEGER NOT NULL DEFAULT 0,
+		total INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL,
+		started_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS scan_run_files (
+		run_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		risk_score INTEGER NOT NULL,
+		timed_out INTEGER NOT NULL DEFAULT 0,
+		size INTEGER NOT NULL DEFAULT 0,
+		mtime INTEGER NOT NULL DEFAULT 0,
+		ruleset TEXT NOT NULL DEFAULT '',
+		result TEXT NOT NULL,
+		archive TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (run_id, path)
+	);
+
 	-- Initialize stats row if it doesn't exist
 	INSERT OR IGNORE INTO stats (id, total_files_scanned, total_risks_found, total_liability)
 	VALUES (1, 0, 0, 0);
 	`
 
-	_, err := s.db.Exec(schema)
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	if err := s.addColumn("audit_history", "job", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn("audit_history", "note", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn("scan_jobs", "catch_up", "TEXT NOT NULL DEFAULT 'once'"); err != nil {
+		return err
+	}
+	if err := s.addColumn("scan_job_state", "anchor", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	// Runs checkpointed before these columns existed are re-analyzed on resume
+	for _, col := range [][2]string{{"size", "INTEGER NOT NULL DEFAULT 0"}, {"mtime", "INTEGER NOT NULL DEFAULT 0"}, {"ruleset", "TEXT NOT NULL DEFAULT ''"}, {"archive", "TEXT NOT NULL DEFAULT ''"}} {
+		if err := s.addColumn("scan_run_files", col[0], col[1]); err != nil {
+			return err
+		}
+	}
+	if err := s.addColumn("file_fingerprints", "archive", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// addColumn adds a column to a table created by an older version
+func (s *Store) addColumn(table, column, decl string) error {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil && name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
 	return err
 }
 
@@ -188,6 +335,7 @@ func (s *Store) Load() (*ScheduleConfig, error) {
 	config := &ScheduleConfig{
 		ScanPaths:    []string{},
 		AuditHistory: []AuditEntry{},
+		PathRules:    map[string]pathfilter.Rules{},
 	}
 
 	// Load settings
@@ -210,6 +358,12 @@ func (s *Store) Load() (*ScheduleConfig, error) {
 	if val, ok := settings["schedule_enabled"]; ok {
 		config.Enabled = val == "true"
 	}
+	if val, ok := settings["sentinel_enabled"]; ok {
+		config.SentinelEnabled = val == "true"
+	}
+	if val, ok := settings["catch_up"]; ok {
+		config.CatchUp = val
+	}
 	if val, ok := settings["scan_interval_hours"]; ok {
 		fmt.Sscanf(val, "%d", &config.IntervalHours)
 	}
@@ -228,6 +382,12 @@ func (s *Store) Load() (*ScheduleConfig, error) {
 	if val, ok := settings["scan_paths"]; ok {
 		json.Unmarshal([]byte(val), &config.ScanPaths)
 	}
+	if val, ok := settings["scan_rules"]; ok {
+		json.Unmarshal([]byte(val), &config.ScanRules)
+	}
+	if val, ok := settings["path_rules"]; ok {
+		json.Unmarshal([]byte(val), &config.PathRules)
+	}
 
 	// Load stats
 	err = s.db.QueryRow("SELECT total_files_scanned, total_risks_found, total_liability FROM stats WHERE id = 1").
@@ -237,13 +397,13 @@ func (s *Store) Load() (*ScheduleConfig, error) {
 	}
 
 	// Load audit history (last 50)
-	auditRows, err := s.db.Query(`SELECT timestamp, total_files, risk_score, user, status 
+	auditRows, err := s.db.Query(`SELECT timestamp, total_files, risk_score, user, status, job, note 
 		FROM audit_history ORDER BY created_at DESC LIMIT 50`)
 	if err == nil {
 		defer auditRows.Close()
 		for auditRows.Next() {
 			var entry AuditEntry
-			if err := auditRows.Scan(&entry.Timestamp, &entry.TotalFiles, &entry.RiskScore, &entry.User, &entry.Status); err == nil {
+			if err := auditRows.Scan(&entry.Timestamp, &entry.TotalFiles, &entry.RiskScore, &entry.User, &entry.Status, &entry.Job, &entry.Note); err == nil {
 				config.AuditHistory = append(config.AuditHistory, entry)
 			}
 		}
@@ -252,6 +412,12 @@ func (s *Store) Load() (*ScheduleConfig, error) {
 	return config, nil
 }
 
+// RulesFor returns the rules for scanning root: the global rules with the
+// root's own rules layered on top
+func (c *ScheduleConfig) RulesFor(root string) pathfilter.Rules {
+	return c.ScanRules.Merge(c.PathRules[root])
+}
+
 // Save writes the configuration to database
 func (s *Store) Save(config *ScheduleConfig) error {
 	tx, err := s.db.Begin()
@@ -263,6 +429,8 @@ func (s *Store) Save(config *ScheduleConfig) error {
 	// Save settings
 	settings := map[string]string{
 		"schedule_enabled":    fmt.Sprintf("%t", config.Enabled),
+		"sentinel_enabled":    fmt.Sprintf("%t", config.SentinelEnabled),
+		"catch_up":            config.CatchUp,
 		"scan_interval_hours": fmt.Sprintf("%d", config.IntervalHours),
 		"interval_value":      fmt.Sprintf("%d", config.IntervalValue),
 		"interval_unit":       config.IntervalUnit,
@@ -272,6 +440,10 @@ func (s *Store) Save(config *ScheduleConfig) error {
 
 	pathsJSON, _ := json.Marshal(config.ScanPaths)
 	settings["scan_paths"] = string(pathsJSON)
+	rulesJSON, _ := json.Marshal(config.ScanRules)
+	settings["scan_rules"] = string(rulesJSON)
+	pathRulesJSON, _ := json.Marshal(config.PathRules)
+	settings["path_rules"] = string(pathRulesJSON)
 
 	for key, value := range settings {
 		_, err := tx.Exec("INSERT OR REPLACE INTO config (key, value) VALUES (?, ?)", key, value)
@@ -292,8 +464,120 @@ func (s *Store) Save(config *ScheduleConfig) error {
 
 // AddAuditEntry appends a new audit record to history
 func (s *Store) AddAuditEntry(entry AuditEntry) error {
-	_, err := s.db.Exec(`INSERT INTO audit_history (timestamp, total_files, risk_score, user, status) VALUES (?, ?, ?, ?, ?)`,
-		entry.Timestamp, entry.TotalFiles, entry.RiskScore, entry.User, entry.Status)
+	_, err := s.db.Exec(`INSERT INTO audit_history (timestamp, total_files, risk_score, user, status, job, note) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp, entry.TotalFiles, entry.RiskScore, entry.User, entry.Status, entry.Job, entry.Note)
+	return err
+}
+
+// SaveLiabilityModel stores a liability model under its version and makes it
+// the active one. Saving an existing version replaces it.
+func (s *Store) SaveLiabilityModel(model liability.Model) error {
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE liability_models SET active = 0"); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO liability_models (version, model, active) VALUES (?, ?, 1)
+		ON CONFLICT(version) DO UPDATE SET model = excluded.model, active = 1`, model.Version, string(data))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ActivateLiabilityModel switches the active model to a stored version
+func (s *Store) ActivateLiabilityModel(version string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE liability_models SET active = (version = ?)", version)
+	if err != nil {
+		return err
+	}
+	var found int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM liability_models WHERE version = ?", version).Scan(&found); err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 || found == 0 {
+		return fmt.Errorf("liability model version %q not found", version)
+	}
+	return tx.Commit()
+}
+
+// LoadLiabilityModel returns the active liability model, or the built-in
+// default when none has been saved
+func (s *Store) LoadLiabilityModel() (liability.Model, error) {
+	var data string
+	err := s.db.QueryRow("SELECT model FROM liability_models WHERE active = 1 LIMIT 1").Scan(&data)
+	if err == sql.ErrNoRows {
+		return liability.Default(), nil
+	}
+	if err != nil {
+		return liability.Default(), err
+	}
+	return liability.Parse([]byte(data))
+}
+
+// LiabilityModelVersions lists stored model versions, newest first
+func (s *Store) LiabilityModelVersions() ([]string, error) {
+	rows, err := s.db.Query("SELECT version FROM liability_models ORDER BY created_at DESC, version DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, rows.Err()
+}
+
+// GetFingerprint returns the cached result for a path
+func (s *Store) GetFingerprint(path string) (FileFingerprint, bool) {
+	fp := FileFingerprint{Path: path}
+	var result, archive string
+	err := s.db.QueryRow(`SELECT size, mtime, content_hash, ruleset, result, archive FROM file_fingerprints WHERE path = ?`, path).
+		Scan(&fp.Size, &fp.ModTime, &fp.ContentHash, &fp.Ruleset, &result, &archive)
+	if err != nil {
+		return FileFingerprint{}, false
+	}
+	fp.Result = []byte(result)
+	if archive != "" {
+		fp.Archive = []byte(archive)
+	}
+	return fp, true
+}
+
+// PutFingerprint stores or replaces the cached result for a path
+func (s *Store) PutFingerprint(fp FileFingerprint) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO file_fingerprints (path, size, mtime, content_hash, ruleset, result, archive, scanned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		fp.Path, fp.Size, fp.ModTime, fp.ContentHash, fp.Ruleset, string(fp.Result), string(fp.Archive))
+	return err
+}
+
+// ClearFingerprints drops every cached result, forcing a full rescan
+func (s *Store) ClearFingerprints() error {
+	_, err := s.db.Exec("DELETE FROM file_fingerprints")
 	return err
 }
 
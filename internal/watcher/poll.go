@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+
+	"hipaa-app/internal/pathfilter"
+)
+
+// fileState is what the poller compares between walks
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// poller is the portable fallback: it re-walks the paths on an interval and
+// reports files that are new or whose size or mtime changed. Each walk
+// follows the root's rules, so excluded directories are never entered.
+type poller struct {
+	paths    []string
+	rules    map[string]pathfilter.Rules
+	interval time.Duration
+	events   chan string
+	errors   chan error
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newPoller(paths []string, rules map[string]pathfilter.Rules, interval time.Duration) *poller {
+	p := &poller{
+		paths:    paths,
+		rules:    rules,
+		interval: interval,
+		events:   make(chan string, 256),
+		errors:   make(chan error, 16),
+		stop:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *poller) Events() <-chan string { return p.events }
+func (p *poller) Errors() <-chan error  { return p.errors }
+
+func (p *poller) Close() error {
+	p.once.Do(func() { close(p.stop) })
+	return nil
+}
+
+func (p *poller) run() {
+	defer close(p.events)
+
+	// The first walk is a baseline; existing files are the scheduler's job
+	known := p.snapshot()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		current := p.snapshot()
+		for path, state := range current {
+			if prev, ok := known[path]; ok && prev == state {
+				continue
+			}
+			select {
+			case p.events <- path:
+			case <-p.stop:
+				return
+			}
+		}
+		known = current
+	}
+}
+
+func (p *poller) snapshot() map[string]fileState {
+	states := make(map[string]fileState)
+	for _, root := range p.paths {
+		// A fresh filter per walk picks up edited .guardianignore files
+		pathfilter.New(root, p.rules[root]).Walk(func(path string, d fs.DirEntry) error {
+			if info, err := d.Info(); err == nil {
+				states[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+			}
+			return nil
+		})
+	}
+	return states
+}
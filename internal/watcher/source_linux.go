@@ -0,0 +1,174 @@
+//go:build linux
+
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"hipaa-app/internal/pathfilter"
+)
+
+// Directory events we care about: files written or moved in, and new
+// subdirectories (which need their own watch)
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO
+
+// inotifySource follows directories recursively with Linux inotify
+type inotifySource struct {
+	file   *os.File // non-blocking inotify fd, so Close unblocks Read
+	fd     int
+	events chan string
+	errors chan error
+
+	mu      sync.Mutex
+	watches map[int]string // watch descriptor -> directory
+	once    sync.Once
+
+	filters map[string]*pathfilter.Filter // by root; only used by addTree
+}
+
+func newEventSource(paths []string, rules map[string]pathfilter.Rules) (eventSource, string, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, "", fmt.Errorf("inotify_init1: %w", err)
+	}
+
+	s := &inotifySource{
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		fd:      fd,
+		events:  make(chan string, 256),
+		errors:  make(chan error, 16),
+		watches: make(map[int]string),
+		filters: make(map[string]*pathfilter.Filter, len(paths)),
+	}
+	for _, root := range paths {
+		s.filters[root] = pathfilter.New(root, rules[root])
+	}
+	for _, root := range paths {
+		s.addTree(root, false)
+	}
+	if len(s.watches) == 0 {
+		s.file.Close()
+		return nil, "", fmt.Errorf("could not watch any of %v", paths)
+	}
+
+	go s.read()
+	return s, "inotify", nil
+}
+
+func (s *inotifySource) Events() <-chan string { return s.events }
+func (s *inotifySource) Errors() <-chan error  { return s.errors }
+
+func (s *inotifySource) Close() error {
+	var err error
+	s.once.Do(func() { err = s.file.Close() })
+	return err
+}
+
+// addTree watches root and every directory below it that the rules do not
+// exclude, so node_modules and the like do not use up inotify watches. When
+// announce is set (a directory that appeared after the watch started) the
+// files already in it are reported, since their create events happened
+// before the watch.
+func (s *inotifySource) addTree(root string, announce bool) {
+	filter := s.filterFor(root)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if filter != nil && d.IsDir() && !filter.AllowsDir(path) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			if announce {
+				s.emit(path)
+			}
+			return nil
+		}
+		wd, err := syscall.InotifyAddWatch(s.fd, path, inotifyMask)
+		if err != nil {
+			// ENOSPC here means fs.inotify.max_user_watches is exhausted
+			s.fail(fmt.Errorf("watch %s: %w", path, err))
+			return nil
+		}
+		s.mu.Lock()
+		s.watches[wd] = path
+		s.mu.Unlock()
+		return nil
+	})
+}
+
+// filterFor returns the filter of the innermost watched root containing path
+func (s *inotifySource) filterFor(path string) *pathfilter.Filter {
+	var best string
+	for root := range s.filters {
+		if (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	return s.filters[best]
+}
+
+func (s *inotifySource) read() {
+	defer close(s.events)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := s.file.Read(buf)
+		if err != nil {
+			return // closed
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(raw.Len)]
+			offset += syscall.SizeofInotifyEvent + int(raw.Len)
+
+			if raw.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				s.fail(fmt.Errorf("inotify queue overflow, some changes were missed"))
+				continue
+			}
+
+			s.mu.Lock()
+			dir, ok := s.watches[int(raw.Wd)]
+			if raw.Mask&syscall.IN_IGNORED != 0 {
+				delete(s.watches, int(raw.Wd))
+			}
+			s.mu.Unlock()
+			if !ok || raw.Len == 0 {
+				continue
+			}
+
+			path := filepath.Join(dir, string(bytes.TrimRight(nameBytes, "\x00")))
+			if raw.Mask&syscall.IN_ISDIR != 0 {
+				if raw.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+					s.addTree(path, true)
+				}
+				continue
+			}
+			s.emit(path)
+		}
+	}
+}
+
+func (s *inotifySource) emit(path string) {
+	select {
+	case s.events <- path:
+	default:
+		s.fail(fmt.Errorf("event backlog full, dropped %s", path))
+	}
+}
+
+func (s *inotifySource) fail(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
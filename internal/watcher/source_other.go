@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watcher
+
+import "hipaa-app/internal/pathfilter"
+
+// newEventSource has no native backend on this platform; Start falls back
+// to polling
+func newEventSource(paths []string, rules map[string]pathfilter.Rules) (eventSource, string, error) {
+	return nil, "", errNoNativeEvents
+}
@@ -0,0 +1,280 @@
+// Package watcher implements the Active Sentinel: it follows file create and
+// modify events under the scan paths and analyzes new or changed files as
+// soon as they settle, instead of waiting for the next scheduled scan.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"hipaa-app/internal/content"
+	"hipaa-app/internal/pathfilter"
+	"hipaa-app/internal/risk"
+)
+
+const (
+	// DefaultDebounce is how long a file must be quiet before it is analyzed,
+	// so a file being copied or saved in chunks is only scanned once
+	DefaultDebounce = 2 * time.Second
+	// pollInterval is used by the fallback when native events are unavailable
+	pollInterval = 5 * time.Second
+	// analysisWorkers analyze settled files off the event loop, and at most
+	// analysisQueue settled files wait for them; the rest stay pending
+	analysisWorkers = 2
+	analysisQueue   = 64
+)
+
+// eventSource delivers paths of files that were created or modified
+type eventSource interface {
+	Events() <-chan string
+	Errors() <-chan error
+	Close() error
+}
+
+// Watcher analyzes files as they change and emits "sentinel:alert" for risky ones
+type Watcher struct {
+	riskEngine   *risk.RiskEngine
+	eventEmitter func(string, interface{})
+	debounce     time.Duration
+	rulesFor     func(root string) pathfilter.Rules
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	paths   []string
+	backend string
+}
+
+// NewWatcher creates a stopped watcher
+func NewWatcher(engine *risk.RiskEngine, emitEvent func(string, interface{})) *Watcher {
+	return &Watcher{
+		riskEngine:   engine,
+		eventEmitter: emitEvent,
+		debounce:     DefaultDebounce,
+	}
+}
+
+// SetDebounce changes the quiet period; it applies on the next Start
+func (w *Watcher) SetDebounce(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if d > 0 {
+		w.debounce = d
+	}
+}
+
+// SetRules sets the include/exclude rules for each watched root so the
+// sentinel skips what scans skip; it applies on the next Start
+func (w *Watcher) SetRules(rulesFor func(root string) pathfilter.Rules) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rulesFor = rulesFor
+}
+
+// Start watches the given directories recursively, replacing any previous watch
+func (w *Watcher) Start(ctx context.Context, paths []string) error {
+	w.Stop()
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths to watch")
+	}
+
+	w.mu.Lock()
+	rulesFor := w.rulesFor
+	w.mu.Unlock()
+	rules := make(map[string]pathfilter.Rules, len(paths))
+	filters := make(map[string]*pathfilter.Filter, len(paths))
+	for _, root := range paths {
+		if rulesFor != nil {
+			rules[root] = rulesFor(root)
+		}
+		filters[root] = pathfilter.New(root, rules[root])
+	}
+
+	// Backends skip excluded directories themselves, each with its own
+	// filters since a Filter is not safe for concurrent use
+	source, backend, err := newEventSource(paths, rules)
+	if err != nil {
+		fmt.Printf("[Sentinel] Native file events unavailable (%v), falling back to polling\n", err)
+		source, backend = newPoller(paths, rules, pollInterval), "polling"
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	w.mu.Lock()
+	w.cancel = cancel
+	w.done = done
+	w.paths = append([]string(nil), paths...)
+	w.backend = backend
+	debounce := w.debounce
+	w.mu.Unlock()
+
+	fmt.Printf("[Sentinel] Watching %d path(s) using %s\n", len(paths), backend)
+	go w.run(ctx, source, debounce, filters, done)
+
+	w.notify("sentinel:status", w.Status())
+	return nil
+}
+
+// Stop ends the current watch, if any, and waits for its event loop to shut
+// down. An analysis already running finishes in the background without alerting.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.paths = nil
+	w.backend = ""
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+		fmt.Println("[Sentinel] Stopped")
+		w.notify("sentinel:status", w.Status())
+	}
+}
+
+// Status reports whether the sentinel is running, on which paths and how
+func (w *Watcher) Status() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return map[string]interface{}{
+		"running": w.cancel != nil,
+		"paths":   append([]string{}, w.paths...),
+		"backend": w.backend,
+	}
+}
+
+// run collects events and waits for each file to go quiet, then hands it to
+// the analysis workers. It never analyzes itself, so events keep flowing and
+// Stop returns promptly while a large file is being read.
+func (w *Watcher) run(ctx context.Context, source eventSource, debounce time.Duration, filters map[string]*pathfilter.Filter, done chan struct{}) {
+	defer close(done)
+	defer source.Close()
+
+	queue := make(chan string, analysisQueue)
+	for i := 0; i < analysisWorkers; i++ {
+		go w.analyzeQueued(ctx, queue)
+	}
+
+	pending := make(map[string]time.Time) // path -> last event
+	tick := time.NewTicker(debounce / 2)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case path, ok := <-source.Events():
+			if !ok {
+				return
+			}
+			if allowed(filters, path) {
+				pending[path] = time.Now()
+			}
+
+		case err, ok := <-source.Errors():
+			if ok {
+				fmt.Printf("[Sentinel] Watch error: %v\n", err)
+			}
+
+		case now := <-tick.C:
+			for path, last := range pending {
+				if now.Sub(last) < debounce {
+					continue
+				}
+				select {
+				case queue <- path:
+					delete(pending, path)
+				default:
+					// Workers are behind: keep it pending for the next tick
+				}
+			}
+		}
+	}
+}
+
+// analyzeQueued analyzes settled files until the watch is stopped
+func (w *Watcher) analyzeQueued(ctx context.Context, queue <-chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case path := <-queue:
+			w.analyze(ctx, path)
+		}
+	}
+}
+
+// analyze runs the risk engine on one settled file and alerts if it is risky,
+// unless the watch was stopped in the meantime
+func (w *Watcher) analyze(ctx context.Context, path string) {
+	var profiles []risk.RiskProfile
+	if format, err := content.DetectFormat(path); err == nil && format.IsArchive() {
+		archive, err := w.riskEngine.AnalyzeArchive(path, w.riskEngine.ScanOptions().Archives)
+		if err != nil {
+			return
+		}
+		profiles = archive.Members
+	} else {
+		profile, err := w.riskEngine.AnalyzeFileRisk(path)
+		if err != nil {
+			return // deleted, unreadable or unsupported; scans list it as unscanned
+		}
+		profiles = []risk.RiskProfile{profile}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	for _, profile := range profiles {
+		w.alert(profile)
+	}
+}
+
+// alert notifies the frontend about a risky file
+func (w *Watcher) alert(profile risk.RiskProfile) {
+	if profile.RiskScore == 0 {
+		return
+	}
+
+	fmt.Printf("[Sentinel] ALERT: %s (%s, score %d)\n", filepath.Base(profile.FilePath), profile.RiskLabel, profile.RiskScore)
+	w.notify("sentinel:alert", map[string]interface{}{
+		"path":              profile.FilePath,
+		"riskScore":         profile.RiskScore,
+		"riskLabel":         profile.RiskLabel,
+		"findings":          profile.Findings,
+		"details":           profile.Details,
+		"uniqueIndividuals": profile.UniqueIndividuals,
+		"detectedAt":        time.Now().Format(time.RFC3339),
+	})
+}
+
+// allowed applies the rules of the innermost watched root containing path
+func allowed(filters map[string]*pathfilter.Filter, path string) bool {
+	var best string
+	for root := range filters {
+		if (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return true
+	}
+	return filters[best].Allows(path)
+}
+
+func (w *Watcher) notify(event string, data interface{}) {
+	if w.eventEmitter != nil {
+		w.eventEmitter(event, data)
+	}
+}
+
+// errNoNativeEvents is returned by newEventSource on platforms without a native backend
+var errNoNativeEvents = errors.New("no native file events on this platform")
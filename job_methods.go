@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+
+	"hipaa-app/internal/scheduler"
+	"hipaa-app/internal/storage"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Scan Job Methods
+
+// GetScanJobs returns every named scan job
+func (a *App) GetScanJobs() ([]storage.ScanJob, error) {
+	return a.store.ListJobs()
+}
+
+// SaveScanJob creates (ID 0) or updates a scan job and returns its ID
+func (a *App) SaveScanJob(job storage.ScanJob) (int64, error) {
+	if err := scheduler.ValidateJob(job); err != nil {
+		return 0, err
+	}
+	id, err := a.store.SaveJob(job)
+	if err != nil {
+		return 0, err
+	}
+	if a.scheduler != nil {
+		a.scheduler.Reload()
+	}
+	return id, nil
+}
+
+// DeleteScanJob removes a scan job
+func (a *App) DeleteScanJob(id int64) error {
+	if err := a.store.DeleteJob(id); err != nil {
+		return err
+	}
+	if a.scheduler != nil {
+		a.scheduler.Reload()
+	}
+	return nil
+}
+
+// RunScanJob starts a scan job immediately
+func (a *App) RunScanJob(id int64) error {
+	if a.scheduler == nil {
+		return fmt.Errorf("scheduler not initialized")
+	}
+	go a.scheduler.RunJobNow(id)
+	return nil
+}
+
+// GetUpcomingRuns lists the scheduled jobs with their next start, soonest first
+func (a *App) GetUpcomingRuns() []scheduler.JobRun {
+	if a.scheduler == nil {
+		return []scheduler.JobRun{}
+	}
+	return a.scheduler.UpcomingRuns()
+}
+
+// GetInterruptedScans lists scheduled scans that were cut short and can resume
+func (a *App) GetInterruptedScans() ([]storage.ScanRun, error) {
+	return a.store.InterruptedScanRuns()
+}
+
+// ResumeScan runs the job of an interrupted scan, which picks up where it stopped
+func (a *App) ResumeScan(runID int64) error {
+	run, err := a.store.GetScanRun(runID)
+	if err != nil {
+		return err
+	}
+	return a.RunScanJob(run.JobID)
+}
+
+// DiscardScan drops an interrupted scan so the job's next run starts over
+func (a *App) DiscardScan(runID int64) error {
+	return a.store.DeleteScanRun(runID)
+}
+
+// schedulerEmitter forwards scheduler events to the frontend and raises a
+// system dialog when a finished job's notification policy asks for one
+func (a *App) schedulerEmitter() func(string, interface{}) {
+	return func(event string, data interface{}) {
+		runtime.EventsEmit(a.ctx, event, data)
+
+		if event != "scan:scheduled:complete" {
+			return
+		}
+		dataMap, ok := data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		status, _ := dataMap["status"].(string)
+		job, _ := dataMap["job"].(string)
+		switch notify, _ := dataMap["notify"].(string); {
+		case status == "FAILED" && notify != storage.NotifyNever:
+			runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+				Type:    runtime.WarningDialog,
+				Title:   "Scheduled Scan Alert",
+				Message: fmt.Sprintf("Automated scan %q detected HIPAA risks. Review required.", job),
+			})
+		case notify == storage.NotifyAlways:
+			runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+				Type:    runtime.InfoDialog,
+				Title:   "Scheduled Scan Complete",
+				Message: fmt.Sprintf("Automated scan %q finished with status %s.", job, status),
+			})
+		}
+	}
+}
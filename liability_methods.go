@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"hipaa-app/internal/liability"
+)
+
+// loadLiabilityModel applies the stored liability model to the risk engine
+func (a *App) loadLiabilityModel() {
+	if a.store == nil {
+		return
+	}
+	model, err := a.store.LoadLiabilityModel()
+	if err != nil {
+		fmt.Printf("Warning: failed to load liability model, using default: %v\n", err)
+		return
+	}
+	if err := a.riskEngine.SetLiabilityModel(model); err != nil {
+		fmt.Printf("Warning: stored liability model %s is invalid, using default: %v\n", model.Version, err)
+	}
+}
+
+// GetLiabilityModel returns the model currently used to price exposure
+func (a *App) GetLiabilityModel() liability.Model {
+	return a.riskEngine.LiabilityModel()
+}
+
+// SaveLiabilityModel stores a new model version and makes it active
+func (a *App) SaveLiabilityModel(model liability.Model) error {
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	if a.store != nil {
+		if err := a.store.SaveLiabilityModel(model); err != nil {
+			return err
+		}
+	}
+	return a.riskEngine.SetLiabilityModel(model)
+}
+
+// GetLiabilityModelVersions lists the stored model versions, newest first
+func (a *App) GetLiabilityModelVersions() ([]string, error) {
+	if a.store == nil {
+		return []string{}, nil
+	}
+	return a.store.LiabilityModelVersions()
+}
+
+// ActivateLiabilityModel switches back to a previously stored model version
+func (a *App) ActivateLiabilityModel(version string) error {
+	if a.store == nil {
+		return fmt.Errorf("storage unavailable")
+	}
+	if err := a.store.ActivateLiabilityModel(version); err != nil {
+		return err
+	}
+	a.loadLiabilityModel()
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"hipaa-app/internal/pathfilter"
+)
+
+// Scan Rule Methods
+
+// GetScanRules returns the include/exclude rules applied to every scan
+func (a *App) GetScanRules() (pathfilter.Rules, error) {
+	config, err := a.store.Load()
+	if err != nil {
+		return pathfilter.Rules{}, err
+	}
+	return config.ScanRules, nil
+}
+
+// SetScanRules replaces the rules applied to every scan
+func (a *App) SetScanRules(rules pathfilter.Rules) error {
+	if err := validateRules(rules); err != nil {
+		return err
+	}
+	config, err := a.store.Load()
+	if err != nil {
+		return err
+	}
+	config.ScanRules = rules
+	if err := a.store.Save(config); err != nil {
+		return err
+	}
+	a.restartSentinel()
+	return nil
+}
+
+// GetPathRules returns the extra rules for one scan path
+func (a *App) GetPathRules(path string) (pathfilter.Rules, error) {
+	config, err := a.store.Load()
+	if err != nil {
+		return pathfilter.Rules{}, err
+	}
+	return config.PathRules[path], nil
+}
+
+// SetPathRules replaces the extra rules for one scan path; empty rules remove them
+func (a *App) SetPathRules(path string, rules pathfilter.Rules) error {
+	if err := validateRules(rules); err != nil {
+		return err
+	}
+	config, err := a.store.Load()
+	if err != nil {
+		return err
+	}
+	if len(rules.Include) == 0 && len(rules.Exclude) == 0 && rules.MaxDepth == 0 && rules.MaxFileSize == 0 && !rules.SkipHidden {
+		delete(config.PathRules, path)
+	} else {
+		config.PathRules[path] = rules
+	}
+	if err := a.store.Save(config); err != nil {
+		return err
+	}
+	a.restartSentinel()
+	return nil
+}
+
+// GetDefaultExcludes lists the patterns every scan skips unless a rule
+// re-includes them with "!"
+func (a *App) GetDefaultExcludes() []string {
+	return pathfilter.DefaultExcludes()
+}
+
+// scanRulesFor returns the stored rules for a scan root
+func (a *App) scanRulesFor(root string) pathfilter.Rules {
+	config, err := a.store.Load()
+	if err != nil {
+		return pathfilter.Rules{}
+	}
+	return config.RulesFor(root)
+}
+
+func validateRules(rules pathfilter.Rules) error {
+	if rules.MaxDepth < 0 {
+		return fmt.Errorf("max depth cannot be negative")
+	}
+	if rules.MaxFileSize < 0 {
+		return fmt.Errorf("max file size cannot be negative")
+	}
+	return nil
+}
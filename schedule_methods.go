@@ -1,10 +1,10 @@
 package main
 
 import (
+	"time"
+
 	"hipaa-app/internal/scheduler"
 	"hipaa-app/internal/storage"
-	
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // Schedule Configuration Methods
@@ -20,21 +20,35 @@ func (a *App) GetScheduleConfig() (storage.ScheduleConfig, error) {
 
 // UpdateScheduleConfig saves a new schedule configuration
 func (a *App) UpdateScheduleConfig(config storage.ScheduleConfig) error {
+	if err := storage.ValidateCatchUp(config.CatchUp); err != nil {
+		return err
+	}
+	previous, err := a.store.Load()
+	if err != nil {
+		return err
+	}
 	if err := a.store.Save(&config); err != nil {
 		return err
 	}
+	// A changed schedule counts its next run from now, not from the old one
+	if !previous.SameSchedule(&config) {
+		if err := a.store.ResetJobSchedule(0); err != nil {
+			return err
+		}
+	}
 	
 	// Restart scheduler with new config
 	if a.scheduler != nil {
 		a.scheduler.Stop()
 	}
 	
-	eventHandler := func(event string, data interface{}) {
-		runtime.EventsEmit(a.ctx, event, data)
+	a.scheduler = scheduler.NewScheduler(a.riskEngine, a.pdfService, a.store, a.schedulerEmitter())
+	if err := a.scheduler.Start(a.ctx); err != nil {
+		return err
 	}
 	
-	a.scheduler = scheduler.NewScheduler(a.riskEngine, a.pdfService, a.store, eventHandler)
-	return a.scheduler.Start(a.ctx)
+	a.restartSentinel()
+	return nil
 }
 
 // GetAuditHistory returns the stored audit history
@@ -61,7 +75,11 @@ func (a *App) AddSchedulePath(path string) error {
 	}
 	
 	config.ScanPaths = append(config.ScanPaths, path)
-	return a.store.Save(config)
+	if err := a.store.Save(config); err != nil {
+		return err
+	}
+	a.restartSentinel()
+	return nil
 }
 
 // RemoveSchedulePath removes a path from the scheduled scan list
@@ -79,5 +97,22 @@ func (a *App) RemoveSchedulePath(path string) error {
 	}
 	
 	config.ScanPaths = filtered
-	return a.store.Save(config)
+	if err := a.store.Save(config); err != nil {
+		return err
+	}
+	a.restartSentinel()
+	return nil
+}
+
+// NextRunAt returns when the next scheduled scan starts (RFC 3339), or an
+// empty string when scheduled scans are off
+func (a *App) NextRunAt() string {
+	if a.scheduler == nil {
+		return ""
+	}
+	next := a.scheduler.NextRunAt()
+	if next.IsZero() {
+		return ""
+	}
+	return next.Format(time.RFC3339)
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Active Sentinel Methods
+
+// startSentinelIfEnabled starts the watcher on the scan paths when the stored
+// config has it switched on
+func (a *App) startSentinelIfEnabled() {
+	if a.store == nil || a.sentinel == nil {
+		return
+	}
+	config, err := a.store.Load()
+	if err != nil || !config.SentinelEnabled || len(config.ScanPaths) == 0 {
+		return
+	}
+	if err := a.sentinel.Start(a.ctx, config.ScanPaths); err != nil {
+		fmt.Printf("[Sentinel] Failed to start: %v\n", err)
+	}
+}
+
+// SetSentinelEnabled turns real-time watching of the scan paths on or off
+// and remembers the choice
+func (a *App) SetSentinelEnabled(enabled bool) error {
+	if a.sentinel == nil {
+		return fmt.Errorf("sentinel not initialized")
+	}
+	config, err := a.store.Load()
+	if err != nil {
+		return err
+	}
+	config.SentinelEnabled = enabled
+	if err := a.store.Save(config); err != nil {
+		return err
+	}
+
+	if !enabled {
+		a.sentinel.Stop()
+		return nil
+	}
+	if len(config.ScanPaths) == 0 {
+		return fmt.Errorf("add a scan path before enabling the sentinel")
+	}
+	return a.sentinel.Start(a.ctx, config.ScanPaths)
+}
+
+// GetSentinelStatus reports whether the sentinel is running and what it watches
+func (a *App) GetSentinelStatus() map[string]interface{} {
+	if a.sentinel == nil {
+		return map[string]interface{}{"running": false, "paths": []string{}, "backend": ""}
+	}
+	return a.sentinel.Status()
+}
+
+// restartSentinel picks up changed scan paths if the sentinel is running
+func (a *App) restartSentinel() {
+	if a.sentinel == nil {
+		return
+	}
+	if running, _ := a.sentinel.Status()["running"].(bool); running {
+		a.sentinel.Stop()
+	}
+	a.startSentinelIfEnabled()
+}
+
+// sentinelEmitter forwards watcher events to the frontend
+func (a *App) sentinelEmitter() func(string, interface{}) {
+	return func(event string, data interface{}) {
+		runtime.EventsEmit(a.ctx, event, data)
+	}
+}